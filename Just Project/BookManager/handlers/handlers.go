@@ -1,8 +1,15 @@
 package handlers
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"thirdproject/models"
@@ -16,6 +23,10 @@ const (
 
 // Handler хранит зависимости для всех HTTP-обработчиков
 type Handler struct {
+	// Dev включает отладочные маршруты (POST /api/books/reset);
+	// задаётся до RegisterRoutes и не должен включаться в продакшне
+	Dev bool
+
 	store *models.Store
 }
 
@@ -38,68 +49,268 @@ func writeError(w http.ResponseWriter, status int, msg string) {
 	writeJSON(w, status, map[string]string{"error": msg})
 }
 
-// parseID извлекает числовой ID из последнего сегмента URL (/api/books/42 → 42)
+// writeStoreError переводит ошибку хранилища в HTTP-статус:
+// нет книги — 404, повтор ISBN или нехватка экземпляров — 409,
+// остальное — ошибка валидации (400)
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		writeError(w, http.StatusNotFound, errNotFound)
+	case errors.Is(err, models.ErrDuplicateISBN),
+		errors.Is(err, models.ErrNoCopies),
+		errors.Is(err, models.ErrAllReturned):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusBadRequest, err.Error())
+	}
+}
+
+// parseID извлекает числовой ID из сегмента {id} маршрута (/api/books/42 → 42)
 func parseID(r *http.Request) (int, error) {
-	parts := strings.Split(strings.TrimRight(r.URL.Path, "/"), "/")
-	return strconv.Atoi(parts[len(parts)-1])
+	return strconv.Atoi(r.PathValue("id"))
 }
 
-// ---------- маршрутизатор ----------
+// etag вычисляет сильный ETag книги как хэш её JSON-представления:
+// любое видимое клиенту изменение книги меняет ETag
+func etag(book models.Book) string {
+	data, _ := json.Marshal(book)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
 
-// BooksRouter направляет запросы к /api/books и /api/books/{id}
-func (h *Handler) BooksRouter(w http.ResponseWriter, r *http.Request) {
-	// Включаем CORS для удобства разработки
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// etagMatches проверяет заголовок If-None-Match: список ETag через запятую
+// или "*". Для GET сравнение слабое, поэтому префикс W/ не учитывается.
+func etagMatches(header, tag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
 
-	if r.Method == http.MethodOptions {
-		w.WriteHeader(http.StatusNoContent)
-		return
+// decodeBook читает книгу из JSON, отклоняя неизвестные поля: опечатка
+// вроде "tittle" даёт ошибку, а не книгу с пустым названием
+func decodeBook(body io.Reader) (models.Book, error) {
+	var book models.Book
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&book); err != nil {
+		// encoding/json не экспортирует тип этой ошибки, только текст
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return book, fmt.Errorf("неизвестное поле %s", field)
+		}
+		return book, errors.New("неверный формат JSON")
 	}
+	return book, nil
+}
 
-	// /api/books  → коллекция
-	// /api/books/ → тоже коллекция
-	// /api/books/42 → конкретная книга
-	path := strings.TrimRight(r.URL.Path, "/")
-	isCollection := path == "/api/books"
+// parseBoolParam читает логический query-параметр; отсутствие — false
+func parseBoolParam(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("некорректный параметр %s", name)
+	}
+	return b, nil
+}
 
-	if isCollection {
-		switch r.Method {
-		case http.MethodGet:
-			h.GetAllBooks(w, r)
-		case http.MethodPost:
-			h.CreateBook(w, r)
-		default:
-			writeError(w, http.StatusMethodNotAllowed, "метод не поддерживается")
+// parseIDList разбирает список ID через запятую ("1,2,3")
+func parseIDList(raw string) ([]int, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, errors.New("параметр ids обязателен")
+	}
+	var ids []int
+	for _, part := range strings.Split(raw, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("некорректный ID %q", part)
 		}
-		return
+		ids = append(ids, id)
 	}
+	return ids, nil
+}
 
-	// Работа с конкретной книгой
-	switch r.Method {
-	case http.MethodGet:
-		h.GetBook(w, r)
-	case http.MethodPut:
-		h.UpdateBook(w, r)
-	case http.MethodDelete:
-		h.DeleteBook(w, r)
-	default:
-		writeError(w, http.StatusMethodNotAllowed, "метод не поддерживается")
+// parseFilter собирает BookFilter из query-параметров author, title, year,
+// genre и include_deleted
+func parseFilter(r *http.Request) (models.BookFilter, error) {
+	q := r.URL.Query()
+	f := models.BookFilter{
+		Author: strings.TrimSpace(q.Get("author")),
+		Title:  strings.TrimSpace(q.Get("title")),
+		Genre:  strings.TrimSpace(q.Get("genre")),
+	}
+	if y := q.Get("year"); y != "" {
+		year, err := strconv.Atoi(y)
+		if err != nil {
+			return f, errors.New("некорректный параметр year")
+		}
+		f.Year = year
+	}
+	var err error
+	if f.IncludeDeleted, err = parseBoolParam(r, "include_deleted"); err != nil {
+		return f, err
 	}
+	return f, nil
+}
+
+// parseIntParam читает целочисленный query-параметр; отсутствие — 0
+func parseIntParam(r *http.Request, name string) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("некорректный параметр %s", name)
+	}
+	return n, nil
+}
+
+// parseSearch собирает SearchOptions: фильтр из parseFilter, а также
+// q, sort, offset и limit
+func parseSearch(r *http.Request) (models.SearchOptions, error) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		return models.SearchOptions{}, err
+	}
+
+	q := r.URL.Query()
+	opts := models.SearchOptions{
+		BookFilter: filter,
+		Query:      strings.TrimSpace(q.Get("q")),
+		Sort:       q.Get("sort"),
+	}
+	if opts.Offset, err = parseIntParam(r, "offset"); err != nil {
+		return opts, err
+	}
+	if opts.Limit, err = parseIntParam(r, "limit"); err != nil {
+		return opts, err
+	}
+	return opts, opts.Validate()
+}
+
+// ---------- маршрутизатор ----------
+
+// RegisterRoutes регистрирует API-маршруты книг и авторов в mux.
+// Шаблоны Go 1.22 сами разбирают метод и {id}; на неподдерживаемый
+// метод ServeMux отвечает 405 с заголовком Allow.
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	api := http.NewServeMux()
+
+	// Коллекция: /api/books и /api/books/
+	api.HandleFunc("GET /api/books", h.GetAllBooks)
+	api.HandleFunc("GET /api/books/{$}", h.GetAllBooks)
+	api.HandleFunc("POST /api/books", h.CreateBook)
+	api.HandleFunc("POST /api/books/{$}", h.CreateBook)
+	api.HandleFunc("DELETE /api/books", h.DeleteBooks)
+	api.HandleFunc("DELETE /api/books/{$}", h.DeleteBooks)
+	api.HandleFunc("POST /api/books/bulk", h.BulkCreateBooks)
+	api.HandleFunc("GET /api/books/export", h.ExportBooks)
+	api.HandleFunc("POST /api/books/import", h.ImportBooks)
+	api.HandleFunc("GET /api/books/search", h.SearchBooks)
+	api.HandleFunc("GET /api/books/count", h.CountBooks)
+	api.HandleFunc("GET /api/books/stats", h.BookStats)
+
+	// Конкретная книга
+	api.HandleFunc("GET /api/books/{id}", h.GetBook)
+	api.HandleFunc("PUT /api/books/{id}", h.UpdateBook)
+	api.HandleFunc("PATCH /api/books/{id}", h.PatchBook)
+	api.HandleFunc("DELETE /api/books/{id}", h.DeleteBook)
+	api.HandleFunc("POST /api/books/{id}/rating", h.RateBook)
+	api.HandleFunc("POST /api/books/{id}/restore", h.RestoreBook)
+	api.HandleFunc("POST /api/books/{id}/borrow", h.BorrowBook)
+	api.HandleFunc("POST /api/books/{id}/return", h.ReturnBook)
+	api.HandleFunc("GET /api/books/{id}/related", h.RelatedBooks)
+
+	if h.Dev {
+		api.HandleFunc("POST /api/books/reset", h.ResetBooks)
+	}
+
+	api.HandleFunc("GET /api/authors", h.ListAuthors)
+
+	mux.Handle("/api/books", withCORS(api))
+	mux.Handle("/api/books/", withCORS(api))
+	mux.Handle("/api/authors", withCORS(api))
+}
+
+// withCORS добавляет CORS-заголовки ко всем ответам и сам отвечает
+// на preflight-запросы OPTIONS
+func withCORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Включаем CORS для удобства разработки
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, If-None-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
 }
 
 // ---------- CRUD-обработчики ----------
 
-// GetAllBooks   GET /api/books
-// Возвращает список всех книг
+// GetAllBooks   GET /api/books[?author=...&title=...&year=...&genre=...&q=...&include_deleted=true&sort=...&offset=...&limit=...]
+// Возвращает список книг; параметры запроса фильтруют, сортируют и
+// разбивают результат на страницы (см. models.Store.Search).
+// Удалённые книги показываются только с include_deleted=true.
 func (h *Handler) GetAllBooks(w http.ResponseWriter, r *http.Request) {
-	books := h.store.GetAll()
-	writeJSON(w, http.StatusOK, books)
+	opts, err := parseSearch(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, h.store.Search(opts))
+}
+
+// SearchBooks   GET /api/books/search?q=...
+// Ищет q в названии и авторе; лучшие совпадения идут первыми.
+// Принимает те же фильтры, sort, offset и limit, что и GetAllBooks.
+func (h *Handler) SearchBooks(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseSearch(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if opts.Query == "" {
+		writeError(w, http.StatusBadRequest, "параметр q обязателен")
+		return
+	}
+	writeJSON(w, http.StatusOK, h.store.Search(opts))
+}
+
+// CountBooks   GET /api/books/count
+// Возвращает общее число книг: {"count": N}
+func (h *Handler) CountBooks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]int{"count": h.store.Count()})
+}
+
+// statsTopAuthors — сколько авторов попадает в top_authors
+const statsTopAuthors = 5
+
+// BookStats   GET /api/books/stats
+// Возвращает число книг, распределение по десятилетиям и самых частых авторов
+func (h *Handler) BookStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.store.Stats(statsTopAuthors))
+}
+
+// ListAuthors   GET /api/authors
+// Возвращает авторов с числом книг: [{"author": "...", "count": N}]
+func (h *Handler) ListAuthors(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.store.Authors())
 }
 
 // GetBook   GET /api/books/{id}
-// Возвращает книгу по ID
+// Возвращает книгу по ID с заголовком ETag; если клиент прислал тот же
+// ETag в If-None-Match, отвечает 304 без тела
 func (h *Handler) GetBook(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r)
 	if err != nil {
@@ -113,26 +324,142 @@ func (h *Handler) GetBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tag := etag(book)
+	w.Header().Set("ETag", tag)
+	if etagMatches(r.Header.Get("If-None-Match"), tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 	writeJSON(w, http.StatusOK, book)
 }
 
-// CreateBook   POST /api/books
-// Создаёт новую книгу из тела запроса (JSON)
-func (h *Handler) CreateBook(w http.ResponseWriter, r *http.Request) {
-	var book models.Book
-	if err := json.NewDecoder(r.Body).Decode(&book); err != nil {
-		writeError(w, http.StatusBadRequest, "неверный формат JSON")
+// relatedLimit — сколько книг того же автора отдаёт /related
+const relatedLimit = 5
+
+// RelatedBooks   GET /api/books/{id}/related
+// Возвращает до relatedLimit других книг того же автора
+func (h *Handler) RelatedBooks(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errBadID)
 		return
 	}
-	if book.Title == "" || book.Author == "" {
-		writeError(w, http.StatusBadRequest, "поля title и author обязательны")
+
+	books, err := h.store.Related(id, relatedLimit)
+	if err != nil {
+		writeStoreError(w, err)
 		return
 	}
+	writeJSON(w, http.StatusOK, books)
+}
 
-	created := h.store.Create(book)
+// CreateBook   POST /api/books[?force=true]
+// Создаёт новую книгу из тела запроса (JSON). Если книга с тем же названием
+// и автором уже есть, возвращает 409 с её ID; force=true отключает проверку.
+func (h *Handler) CreateBook(w http.ResponseWriter, r *http.Request) {
+	force, err := parseBoolParam(r, "force")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	book, err := decodeBook(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := book.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var created models.Book
+	if force {
+		created, err = h.store.Create(book)
+	} else {
+		var id int
+		created, id, err = h.store.CreateUnique(book)
+		if errors.Is(err, models.ErrDuplicateBook) {
+			writeJSON(w, http.StatusConflict, map[string]any{
+				"error": err.Error(),
+				"id":    id,
+			})
+			return
+		}
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
 	writeJSON(w, http.StatusCreated, created)
 }
 
+// BulkResponse — ответ на пакетное создание книг
+type BulkResponse struct {
+	Created []models.Book      `json:"created"`
+	Errors  []models.ItemError `json:"errors"`
+}
+
+// BulkCreateBooks   POST /api/books/bulk
+// Создаёт книги из JSON-массива. Корректные элементы сохраняются,
+// для остальных возвращается ошибка с индексом элемента.
+// 201 — если создана хотя бы одна книга, иначе 400.
+func (h *Handler) BulkCreateBooks(w http.ResponseWriter, r *http.Request) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+		writeError(w, http.StatusBadRequest, "ожидается JSON-массив книг")
+		return
+	}
+	if len(items) == 0 {
+		writeError(w, http.StatusBadRequest, "пустой список книг")
+		return
+	}
+
+	// Элементы, которые не декодируются в Book, сразу считаем ошибками;
+	// positions связывает индекс в books с индексом во входном массиве.
+	var (
+		books     []models.Book
+		positions []int
+		errs      []models.ItemError
+	)
+	for i, raw := range items {
+		b, err := decodeBook(bytes.NewReader(raw))
+		if err != nil {
+			errs = append(errs, models.ItemError{Index: i, Error: err.Error()})
+			continue
+		}
+		books = append(books, b)
+		positions = append(positions, i)
+	}
+
+	h.createMany(w, books, positions, nil, errs)
+}
+
+// createMany сохраняет books одним вызовом CreateMany и отвечает
+// BulkResponse. positions[i] — позиция books[i] во входных данных,
+// lines[i] — её строка в файле (nil, если входные данные не файл), errs —
+// ошибки разбора, найденные до сохранения. 201 — если создана хотя бы одна
+// книга, иначе 400.
+func (h *Handler) createMany(w http.ResponseWriter, books []models.Book, positions, lines []int, errs []models.ItemError) {
+	created, storeErrs := h.store.CreateMany(books)
+	for _, e := range storeErrs {
+		if lines != nil {
+			e.Line = lines[e.Index]
+		}
+		e.Index = positions[e.Index]
+		errs = append(errs, e)
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Index < errs[j].Index })
+	if errs == nil {
+		errs = []models.ItemError{}
+	}
+
+	status := http.StatusCreated
+	if len(created) == 0 {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, BulkResponse{Created: created, Errors: errs})
+}
+
 // UpdateBook   PUT /api/books/{id}
 // Полностью заменяет книгу по ID
 func (h *Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
@@ -142,38 +469,177 @@ func (h *Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var book models.Book
-	if err := json.NewDecoder(r.Body).Decode(&book); err != nil {
-		writeError(w, http.StatusBadRequest, "неверный формат JSON")
+	book, err := decodeBook(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if book.Title == "" || book.Author == "" {
-		writeError(w, http.StatusBadRequest, "поля title и author обязательны")
+	updated, err := h.store.Update(id, book)
+	if err != nil {
+		writeStoreError(w, err)
 		return
 	}
 
-	updated, ok := h.store.Update(id, book)
-	if !ok {
-		writeError(w, http.StatusNotFound, errNotFound)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// PatchBook   PATCH /api/books/{id}
+// Частично обновляет книгу: меняются только поля, переданные в JSON
+func (h *Handler) PatchBook(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errBadID)
+		return
+	}
+
+	var fields map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, "неверный формат JSON")
+		return
+	}
+
+	updated, err := h.store.Patch(id, fields)
+	if err != nil {
+		writeStoreError(w, err)
 		return
 	}
 
 	writeJSON(w, http.StatusOK, updated)
 }
 
-// DeleteBook   DELETE /api/books/{id}
-// Удаляет книгу по ID
+// RateBook   POST /api/books/{id}/rating
+// Принимает {"rating": 4.5} и возвращает книгу с обновлённой средней оценкой
+func (h *Handler) RateBook(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errBadID)
+		return
+	}
+
+	var body struct {
+		Rating *float64 `json:"rating"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "неверный формат JSON")
+		return
+	}
+	if body.Rating == nil {
+		writeError(w, http.StatusBadRequest, "поле rating обязательно")
+		return
+	}
+
+	book, err := h.store.Rate(id, *body.Rating)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, book)
+}
+
+// BorrowBook   POST /api/books/{id}/borrow
+// Выдаёт один экземпляр; 409, если свободных не осталось
+func (h *Handler) BorrowBook(w http.ResponseWriter, r *http.Request) {
+	h.changeCopies(w, r, h.store.Borrow)
+}
+
+// ReturnBook   POST /api/books/{id}/return
+// Принимает один экземпляр обратно; 409, если все уже на месте
+func (h *Handler) ReturnBook(w http.ResponseWriter, r *http.Request) {
+	h.changeCopies(w, r, h.store.Return)
+}
+
+// changeCopies — общая часть BorrowBook и ReturnBook
+func (h *Handler) changeCopies(w http.ResponseWriter, r *http.Request, op func(id int) (models.Book, error)) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errBadID)
+		return
+	}
+
+	book, err := op(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, book)
+}
+
+// ResetBooks   POST /api/books/reset (только с флагом --dev)
+// Возвращает каталог к трём книгам по умолчанию
+func (h *Handler) ResetBooks(w http.ResponseWriter, r *http.Request) {
+	h.store.Reset()
+	writeJSON(w, http.StatusOK, map[string]string{"message": "каталог сброшен"})
+}
+
+// DeleteBook   DELETE /api/books/{id}[?hard=true]
+// Помечает книгу удалённой (её можно восстановить); hard=true удаляет
+// книгу безвозвратно
 func (h *Handler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 	id, err := parseID(r)
 	if err != nil {
 		writeError(w, http.StatusBadRequest, errBadID)
 		return
 	}
+	hard, err := parseBoolParam(r, "hard")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	if !h.store.Delete(id) {
-		writeError(w, http.StatusNotFound, errNotFound)
+	if hard {
+		if !h.store.Delete(id) {
+			writeError(w, http.StatusNotFound, errNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": "книга удалена безвозвратно"})
 		return
 	}
 
+	if err := h.store.SoftDelete(id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
 	writeJSON(w, http.StatusOK, map[string]string{"message": "книга удалена"})
 }
+
+// BatchDeleteResponse — ответ DeleteBooks: какие ID удалены, каких нет
+type BatchDeleteResponse struct {
+	Deleted  []int `json:"deleted"`
+	NotFound []int `json:"not_found"`
+}
+
+// DeleteBooks   DELETE /api/books?ids=1,2,3[&hard=true]
+// Удаляет несколько книг за раз, так же как DeleteBook. Отсутствующие ID
+// не прерывают удаление и перечисляются в not_found.
+func (h *Handler) DeleteBooks(w http.ResponseWriter, r *http.Request) {
+	ids, err := parseIDList(r.URL.Query().Get("ids"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	hard, err := parseBoolParam(r, "hard")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	deleted, notFound := h.store.DeleteMany(ids, hard)
+	writeJSON(w, http.StatusOK, BatchDeleteResponse{Deleted: deleted, NotFound: notFound})
+}
+
+// RestoreBook   POST /api/books/{id}/restore
+// Восстанавливает удалённую книгу
+func (h *Handler) RestoreBook(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errBadID)
+		return
+	}
+
+	book, err := h.store.Restore(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, book)
+}
@@ -0,0 +1,832 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"thirdproject/models"
+	"time"
+)
+
+const errDecodeFmt = "decode error: %v"
+
+// serve прогоняет запрос через маршруты нового Handler с тестовыми данными.
+func serve(t *testing.T, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	return serveWith(New(models.NewStore()), req)
+}
+
+func serveWith(h *Handler, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetAllBooksFilterByAuthor(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodGet, "/api/books?author=HUNT", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var books []models.Book
+	if err := json.NewDecoder(rec.Body).Decode(&books); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if len(books) != 1 || books[0].Author != "Andrew Hunt" {
+		t.Errorf("expected only Andrew Hunt's book, got %+v", books)
+	}
+}
+
+func TestGetAllBooksBadYear(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodGet, "/api/books?year=abc", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestGetAllBooksSort(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodGet, "/api/books?sort=-year", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var books []models.Book
+	if err := json.NewDecoder(rec.Body).Decode(&books); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	for i := 1; i < len(books); i++ {
+		if books[i-1].Year < books[i].Year {
+			t.Fatalf("expected descending years, got %+v", books)
+		}
+	}
+}
+
+func TestGetAllBooksUnknownSort(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodGet, "/api/books?sort=pages", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
+
+func TestPatchBookYearOnly(t *testing.T) {
+	h := New(models.NewStore())
+	before, _ := h.store.GetByID(1)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/books/1", strings.NewReader(`{"year": 2016}`))
+	rec := serveWith(h, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	var got models.Book
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if got.Year != 2016 || got.Title != before.Title || got.Author != before.Author {
+		t.Errorf("expected only year to change, got %+v (was %+v)", got, before)
+	}
+}
+
+func TestPatchBookErrors(t *testing.T) {
+	tests := []struct {
+		path, body string
+		want       int
+	}{
+		{"/api/books/1", `{"year": "soon"}`, http.StatusBadRequest},
+		{"/api/books/1", `not json`, http.StatusBadRequest},
+		{"/api/books/99", `{"year": 2000}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := serve(t, httptest.NewRequest(http.MethodPatch, tt.path, strings.NewReader(tt.body)))
+		if rec.Code != tt.want {
+			t.Errorf("PATCH %s %s: expected %d, got %d", tt.path, tt.body, tt.want, rec.Code)
+		}
+	}
+}
+
+func TestCreateBookYearValidation(t *testing.T) {
+	tests := []struct {
+		body string
+		want int
+	}{
+		{`{"title":"Clean Architecture","author":"Robert C. Martin","year":2017}`, http.StatusCreated},
+		{`{"title":"Clean Architecture","author":"Robert C. Martin","year":0}`, http.StatusBadRequest},
+		{`{"title":"Clean Architecture","author":"Robert C. Martin","year":3000}`, http.StatusBadRequest},
+		{`{"title":"","author":"Robert C. Martin","year":2017}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := serve(t, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(tt.body)))
+		if rec.Code != tt.want {
+			t.Errorf("POST %s: expected %d, got %d: %s", tt.body, tt.want, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestUpdateBookOutOfRangeYear(t *testing.T) {
+	body := `{"title":"Clean Code","author":"Robert C. Martin","year":1200}`
+	rec := serve(t, httptest.NewRequest(http.MethodPut, "/api/books/2", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+
+	var resp map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if !strings.Contains(resp["error"], "year") {
+		t.Errorf("expected error about year, got %q", resp["error"])
+	}
+}
+
+func TestBulkCreateBooksPartial(t *testing.T) {
+	body := `[
+		{"title":"Refactoring","author":"Martin Fowler","year":1999},
+		{"title":"No Author","year":2000},
+		{"title":"Bad","author":"Types","year":"1999"},
+		{"title":"Domain-Driven Design","author":"Eric Evans","year":2003}
+	]`
+	rec := serve(t, httptest.NewRequest(http.MethodPost, "/api/books/bulk", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+
+	var resp BulkResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if len(resp.Created) != 2 || resp.Created[0].Title != "Refactoring" || resp.Created[1].Title != "Domain-Driven Design" {
+		t.Errorf("unexpected created books: %+v", resp.Created)
+	}
+	if len(resp.Errors) != 2 || resp.Errors[0].Index != 1 || resp.Errors[1].Index != 2 {
+		t.Errorf("expected errors for items 1 and 2, got %+v", resp.Errors)
+	}
+}
+
+func TestBulkCreateBooksNoneValid(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodPost, "/api/books/bulk", strings.NewReader(`[{"title":"x"}]`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+
+	rec = serve(t, httptest.NewRequest(http.MethodPost, "/api/books/bulk", strings.NewReader(`{"title":"x"}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for non-array body, got %d", rec.Code)
+	}
+}
+
+func TestCreateBookISBN(t *testing.T) {
+	h := New(models.NewStore())
+	tests := []struct {
+		title, isbn string
+		want        int
+	}{
+		{"Refactoring", "978-0-201-48567-7", http.StatusCreated},
+		{"Refactoring (bad ISBN)", "978-0-201-48567-0", http.StatusBadRequest},
+		{"Refactoring (same ISBN)", "9780201485677", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		body := `{"title":"` + tt.title + `","author":"Martin Fowler","year":1999,"isbn":"` + tt.isbn + `"}`
+		rec := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(body)))
+		if rec.Code != tt.want {
+			t.Errorf("POST isbn=%s: expected %d, got %d: %s", tt.isbn, tt.want, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestGetAllBooksFilterByGenre(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodGet, "/api/books?genre=go", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var books []models.Book
+	if err := json.NewDecoder(rec.Body).Decode(&books); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if len(books) != 1 || books[0].Title != "The Go Programming Language" {
+		t.Errorf("expected only the Go book, got %+v", books)
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/books", http.StatusOK},
+		{http.MethodGet, "/api/books/", http.StatusOK},
+		{http.MethodGet, "/api/books/2", http.StatusOK},
+		{http.MethodGet, "/api/books/99", http.StatusNotFound},
+		{http.MethodGet, "/api/books/abc", http.StatusBadRequest},
+		{http.MethodPut, "/api/books/abc", http.StatusBadRequest},
+		{http.MethodPatch, "/api/books/abc", http.StatusBadRequest},
+		{http.MethodDelete, "/api/books/abc", http.StatusBadRequest},
+		{http.MethodDelete, "/api/books/3", http.StatusOK},
+		{http.MethodDelete, "/api/books", http.StatusBadRequest},
+		{http.MethodPut, "/api/books", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/api/books/1/extra", http.StatusNotFound},
+		{http.MethodGet, "/api/books/stats", http.StatusOK},
+	}
+	for _, tt := range tests {
+		rec := serve(t, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.want, rec.Code)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("%s %s: expected CORS header, got %q", tt.method, tt.path, got)
+		}
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodOptions, "/api/books/1", nil))
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(got, "PATCH") {
+		t.Errorf("expected PATCH in allowed methods, got %q", got)
+	}
+}
+
+func TestRateBook(t *testing.T) {
+	h := New(models.NewStore())
+
+	var got models.Book
+	for _, body := range []string{`{"rating": 5}`, `{"rating": 2}`} {
+		rec := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books/2/rating", strings.NewReader(body)))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("POST rating %s: expected 200, got %d: %s", body, rec.Code, rec.Body)
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf(errDecodeFmt, err)
+		}
+	}
+	if got.Rating != 3.5 {
+		t.Errorf("expected average rating 3.5, got %v", got.Rating)
+	}
+
+	for _, body := range []string{`{"rating": 6}`, `{"rating": -0.5}`, `{}`} {
+		rec := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books/2/rating", strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST rating %s: expected 400, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestExportBooksCSV(t *testing.T) {
+	h := New(models.NewStore())
+	tricky := `{"title":"Programming Pearls, 2nd \"Edition\"","author":"Jon Bentley","year":1999}`
+	if rec := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(tricky))); rec.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d", rec.Code)
+	}
+
+	rec := serveWith(h, httptest.NewRequest(http.MethodGet, "/api/books/export", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("expected text/csv, got %q", ct)
+	}
+	if cd := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, "attachment") {
+		t.Errorf("expected attachment disposition, got %q", cd)
+	}
+
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("parse CSV: %v", err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("expected header + 4 rows, got %d: %q", len(rows), rows)
+	}
+	if strings.Join(rows[0], ",") != "id,title,author,year,isbn" {
+		t.Errorf("unexpected header %q", rows[0])
+	}
+	if want := []string{"2", "Clean Code", "Robert C. Martin", "2008", "9780132350884"}; strings.Join(rows[2], "|") != strings.Join(want, "|") {
+		t.Errorf("expected row %q, got %q", want, rows[2])
+	}
+	if rows[4][1] != `Programming Pearls, 2nd "Edition"` || rows[4][4] != "" {
+		t.Errorf("expected escaped title to round-trip, got %q", rows[4])
+	}
+}
+
+func TestGetAllBooksPaging(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []int
+		code  int
+	}{
+		{"?sort=-year&limit=2", []int{1, 2}, http.StatusOK},
+		{"?sort=-year&offset=1&limit=1", []int{2}, http.StatusOK},
+		{"?offset=10", []int{}, http.StatusOK},
+		{"?genre=craftsmanship&q=clean", []int{2}, http.StatusOK},
+		{"?limit=-1", nil, http.StatusBadRequest},
+		{"?offset=abc", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := serve(t, httptest.NewRequest(http.MethodGet, "/api/books"+tt.query, nil))
+		if rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d", tt.query, tt.code, rec.Code)
+			continue
+		}
+		if tt.code != http.StatusOK {
+			continue
+		}
+		var books []models.Book
+		if err := json.NewDecoder(rec.Body).Decode(&books); err != nil {
+			t.Fatalf(errDecodeFmt, err)
+		}
+		ids := make([]int, len(books))
+		for i, b := range books {
+			ids[i] = b.ID
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%s: expected IDs %v, got %v", tt.query, tt.want, ids)
+		}
+	}
+}
+
+func TestSearchBooks(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodGet, "/api/books/search?q=pro", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var books []models.Book
+	if err := json.NewDecoder(rec.Body).Decode(&books); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	// "The Pragmatic Programmer" — совпадение на позиции 14, "The Go Programming Language" — на 7
+	if len(books) != 2 || books[0].ID != 1 || books[1].ID != 3 {
+		t.Errorf("expected books 1 then 3, got %+v", books)
+	}
+
+	rec = serve(t, httptest.NewRequest(http.MethodGet, "/api/books/search?q=pro&year=1999", nil))
+	books = nil
+	if err := json.NewDecoder(rec.Body).Decode(&books); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if len(books) != 1 || books[0].ID != 3 {
+		t.Errorf("expected only book 3 with year filter, got %+v", books)
+	}
+
+	rec = serve(t, httptest.NewRequest(http.MethodGet, "/api/books/search?q=", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for empty q, got %d", rec.Code)
+	}
+}
+
+func TestCountBooks(t *testing.T) {
+	h := New(models.NewStore())
+	count := func() int {
+		t.Helper()
+		rec := serveWith(h, httptest.NewRequest(http.MethodGet, "/api/books/count", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", rec.Code)
+		}
+		var resp map[string]int
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf(errDecodeFmt, err)
+		}
+		return resp["count"]
+	}
+
+	if n := count(); n != 3 {
+		t.Fatalf("expected count 3, got %d", n)
+	}
+	body := `{"title":"Refactoring","author":"Martin Fowler","year":1999}`
+	serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(body)))
+	if n := count(); n != 4 {
+		t.Errorf("expected count 4 after create, got %d", n)
+	}
+	serveWith(h, httptest.NewRequest(http.MethodDelete, "/api/books/2", nil))
+	if n := count(); n != 3 {
+		t.Errorf("expected count 3 after delete, got %d", n)
+	}
+}
+
+func TestCreateBookDuplicate(t *testing.T) {
+	h := New(models.NewStore())
+	body := `{"title":"Refactoring","author":"Martin Fowler","year":1999}`
+
+	first := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(body)))
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first create: expected 201, got %d", first.Code)
+	}
+	var created models.Book
+	if err := json.NewDecoder(first.Body).Decode(&created); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+
+	dup := `{"title":"refactoring ","author":"MARTIN FOWLER","year":2018}`
+	rec := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(dup)))
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("second create: expected 409, got %d", rec.Code)
+	}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if resp.ID != created.ID {
+		t.Errorf("expected existing ID %d, got %d", created.ID, resp.ID)
+	}
+
+	rec = serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books?force=true", strings.NewReader(dup)))
+	if rec.Code != http.StatusCreated {
+		t.Errorf("forced create: expected 201, got %d", rec.Code)
+	}
+	if n := h.store.Count(); n != 5 {
+		t.Errorf("expected 5 books, got %d", n)
+	}
+}
+
+func TestSoftDeleteHidesAndRestores(t *testing.T) {
+	h := New(models.NewStore())
+	list := func(query string) []models.Book {
+		t.Helper()
+		rec := serveWith(h, httptest.NewRequest(http.MethodGet, "/api/books"+query, nil))
+		var books []models.Book
+		if err := json.NewDecoder(rec.Body).Decode(&books); err != nil {
+			t.Fatalf(errDecodeFmt, err)
+		}
+		return books
+	}
+
+	if rec := serveWith(h, httptest.NewRequest(http.MethodDelete, "/api/books/1", nil)); rec.Code != http.StatusOK {
+		t.Fatalf("delete: expected 200, got %d", rec.Code)
+	}
+	if books := list(""); len(books) != 2 {
+		t.Errorf("expected deleted book hidden, got %+v", books)
+	}
+	if rec := serveWith(h, httptest.NewRequest(http.MethodGet, "/api/books/1", nil)); rec.Code != http.StatusNotFound {
+		t.Errorf("get deleted: expected 404, got %d", rec.Code)
+	}
+	if books := list("?include_deleted=true"); len(books) != 3 || !books[0].Deleted {
+		t.Errorf("expected deleted book listed with include_deleted, got %+v", books)
+	}
+
+	rec := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books/1/restore", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("restore: expected 200, got %d", rec.Code)
+	}
+	if books := list(""); len(books) != 3 {
+		t.Errorf("expected restored book visible, got %+v", books)
+	}
+}
+
+func TestHardDelete(t *testing.T) {
+	h := New(models.NewStore())
+
+	if rec := serveWith(h, httptest.NewRequest(http.MethodDelete, "/api/books/1?hard=true", nil)); rec.Code != http.StatusOK {
+		t.Fatalf("hard delete: expected 200, got %d", rec.Code)
+	}
+	if rec := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books/1/restore", nil)); rec.Code != http.StatusNotFound {
+		t.Errorf("restore after hard delete: expected 404, got %d", rec.Code)
+	}
+}
+
+func TestBookTimestampsInResponse(t *testing.T) {
+	body := `{"title":"Refactoring","author":"Martin Fowler","year":1999}`
+	rec := serve(t, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", rec.Code)
+	}
+
+	var resp map[string]any
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	for _, field := range []string{"created_at", "updated_at"} {
+		v, _ := resp[field].(string)
+		if _, err := time.Parse(time.RFC3339, v); err != nil {
+			t.Errorf("expected RFC 3339 %s, got %v", field, resp[field])
+		}
+	}
+}
+
+func TestResetBooksDevOnly(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodPost, "/api/books/reset", nil))
+	if rec.Code == http.StatusOK {
+		t.Fatalf("expected reset to be unavailable without Dev, got %d", rec.Code)
+	}
+
+	h := New(models.NewStore())
+	h.Dev = true
+	serveWith(h, httptest.NewRequest(http.MethodDelete, "/api/books/1?hard=true", nil))
+	body := `{"title":"Refactoring","author":"Martin Fowler","year":1999}`
+	serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(body)))
+
+	rec = serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books/reset", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	books := h.store.Find(models.BookFilter{})
+	if len(books) != 3 || books[0].Title != "The Go Programming Language" || books[2].ID != 3 {
+		t.Errorf("expected seeded catalog after reset, got %+v", books)
+	}
+}
+
+func TestUnknownFieldsRejected(t *testing.T) {
+	tests := []struct {
+		method, path string
+	}{
+		{http.MethodPost, "/api/books"},
+		{http.MethodPut, "/api/books/1"},
+	}
+	body := `{"tittle":"Refactoring","title":"Refactoring","author":"Martin Fowler","year":1999}`
+	for _, tt := range tests {
+		rec := serve(t, httptest.NewRequest(tt.method, tt.path, strings.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s %s: expected 400, got %d", tt.method, tt.path, rec.Code)
+		}
+		var resp map[string]string
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf(errDecodeFmt, err)
+		}
+		if !strings.Contains(resp["error"], `"tittle"`) {
+			t.Errorf("%s %s: expected error naming the field, got %q", tt.method, tt.path, resp["error"])
+		}
+	}
+
+	// Обязательные поля по-прежнему проверяются
+	rec := serve(t, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(`{"author":"Martin Fowler","year":1999}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("missing title: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestBorrowDownToZero(t *testing.T) {
+	h := New(models.NewStore())
+	body := `{"title":"Refactoring","author":"Martin Fowler","year":1999,"total_copies":2}`
+	rec := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(body)))
+	var book models.Book
+	if err := json.NewDecoder(rec.Body).Decode(&book); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if book.AvailableCopies != 2 {
+		t.Fatalf("expected 2 available copies on create, got %d", book.AvailableCopies)
+	}
+	path := "/api/books/" + strconv.Itoa(book.ID)
+
+	for want := 1; want >= 0; want-- {
+		rec := serveWith(h, httptest.NewRequest(http.MethodPost, path+"/borrow", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("borrow: expected 200, got %d", rec.Code)
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&book); err != nil {
+			t.Fatalf(errDecodeFmt, err)
+		}
+		if book.AvailableCopies != want {
+			t.Errorf("expected %d available, got %d", want, book.AvailableCopies)
+		}
+	}
+
+	if rec := serveWith(h, httptest.NewRequest(http.MethodPost, path+"/borrow", nil)); rec.Code != http.StatusConflict {
+		t.Errorf("borrow with none left: expected 409, got %d", rec.Code)
+	}
+	if rec := serveWith(h, httptest.NewRequest(http.MethodPost, path+"/return", nil)); rec.Code != http.StatusOK {
+		t.Errorf("return: expected 200, got %d", rec.Code)
+	}
+}
+
+func TestReturnWhenAllAvailable(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodPost, "/api/books/1/return", nil))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected 409, got %d", rec.Code)
+	}
+}
+
+func TestListAuthorsSeeded(t *testing.T) {
+	rec := serve(t, httptest.NewRequest(http.MethodGet, "/api/authors", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var got []models.AuthorCount
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	want := []models.AuthorCount{
+		{Author: "Alan A. A. Donovan", Count: 1},
+		{Author: "Andrew Hunt", Count: 1},
+		{Author: "Robert C. Martin", Count: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("author %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestGetBookETag(t *testing.T) {
+	h := New(models.NewStore())
+
+	rec := serveWith(h, httptest.NewRequest(http.MethodGet, "/api/books/1", nil))
+	tag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || tag == "" {
+		t.Fatalf("expected 200 with ETag, got %d and %q", rec.Code, tag)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/books/1", nil)
+	req.Header.Set("If-None-Match", tag)
+	rec = serveWith(h, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("matching If-None-Match: expected 304, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("expected empty body for 304, got %q", rec.Body)
+	}
+
+	// После изменения книги старый ETag устаревает
+	serveWith(h, httptest.NewRequest(http.MethodPatch, "/api/books/1", strings.NewReader(`{"year": 2016}`)))
+	req = httptest.NewRequest(http.MethodGet, "/api/books/1", nil)
+	req.Header.Set("If-None-Match", tag)
+	rec = serveWith(h, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stale If-None-Match: expected 200, got %d", rec.Code)
+	}
+	if newTag := rec.Header().Get("ETag"); newTag == tag {
+		t.Errorf("expected ETag to change after update, still %q", newTag)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	tag := `"abc"`
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`*`, true},
+		{`"xyz"`, false},
+		{``, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, tag); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestCreateBookCoverURL(t *testing.T) {
+	h := New(models.NewStore())
+	cover := "https://covers.openlibrary.org/b/isbn/9780201485677-M.jpg"
+
+	body := `{"title":"Refactoring","author":"Martin Fowler","year":1999,"cover_url":"` + cover + `"}`
+	rec := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(body)))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("valid cover: expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var book models.Book
+	if err := json.NewDecoder(rec.Body).Decode(&book); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if stored, _ := h.store.GetByID(book.ID); stored.CoverURL != cover {
+		t.Errorf("expected cover %q stored, got %q", cover, stored.CoverURL)
+	}
+
+	body = `{"title":"Dune","author":"Frank Herbert","year":1965,"cover_url":"ftp://example.com/dune.jpg"}`
+	rec = serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(body)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("ftp cover: expected 400, got %d", rec.Code)
+	}
+}
+
+func TestImportBooksCSV(t *testing.T) {
+	body := "title,author,year,isbn\n" +
+		"Refactoring,Martin Fowler,1999,978-0-201-48567-7\n" +
+		"Bad Year,Someone,nineteen,\n" +
+		"No Author,,2000,\n" +
+		"Domain-Driven Design,Eric Evans,2003,\n" +
+		"Bad \"Quote,Someone,2001,\n" +
+		"Working Effectively with Legacy Code,Michael Feathers,2004,\n" +
+		"\"Unterminated,Quote,2000\n"
+	req := httptest.NewRequest(http.MethodPost, "/api/books/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := serve(t, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+
+	var resp BulkResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if len(resp.Created) != 3 || resp.Created[0].ISBN != "9780201485677" || resp.Created[1].Title != "Domain-Driven Design" ||
+		resp.Created[2].Title != "Working Effectively with Legacy Code" {
+		t.Errorf("unexpected created books: %+v", resp.Created)
+	}
+	var rows, lines []int
+	for _, e := range resp.Errors {
+		rows = append(rows, e.Index)
+		lines = append(lines, e.Line)
+	}
+	if !reflect.DeepEqual(rows, []int{1, 2, 4, 6}) {
+		t.Errorf("expected errors for records 1, 2, 4 and 6, got %+v", resp.Errors)
+	}
+	if !reflect.DeepEqual(lines, []int{3, 4, 6, 8}) {
+		t.Errorf("expected errors on lines 3, 4, 6 and 8, got %+v", resp.Errors)
+	}
+}
+
+func TestImportBooksMultipart(t *testing.T) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", "books.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// без заголовка: колонки по умолчанию title,author,year,isbn
+	fw.Write([]byte("Refactoring,Martin Fowler,1999\n"))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/books/import", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := serve(t, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = serve(t, httptest.NewRequest(http.MethodPost, "/api/books/import", strings.NewReader("title,author\n")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for CSV without books, got %d", rec.Code)
+	}
+}
+
+func TestRelatedBooks(t *testing.T) {
+	h := New(models.NewStore())
+	for _, title := range []string{"Clean Architecture", "The Clean Coder"} {
+		body := `{"title":"` + title + `","author":"Robert C. Martin","year":2012}`
+		if rec := serveWith(h, httptest.NewRequest(http.MethodPost, "/api/books", strings.NewReader(body))); rec.Code != http.StatusCreated {
+			t.Fatalf("create %s: expected 201, got %d", title, rec.Code)
+		}
+	}
+
+	rec := serveWith(h, httptest.NewRequest(http.MethodGet, "/api/books/4/related", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var got []models.Book
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if len(got) != 2 || got[0].ID != 2 || got[1].ID != 5 {
+		t.Errorf("expected books 2 and 5, got %+v", got)
+	}
+
+	if rec := serveWith(h, httptest.NewRequest(http.MethodGet, "/api/books/99/related", nil)); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for missing book, got %d", rec.Code)
+	}
+}
+
+func TestDeleteBooksBatch(t *testing.T) {
+	h := New(models.NewStore())
+	rec := serveWith(h, httptest.NewRequest(http.MethodDelete, "/api/books?ids=1,99,3,1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	var resp BatchDeleteResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if !reflect.DeepEqual(resp.Deleted, []int{1, 3}) || !reflect.DeepEqual(resp.NotFound, []int{99}) {
+		t.Errorf("expected deleted [1 3] and not_found [99], got %+v", resp)
+	}
+	for _, id := range []string{"1", "3"} {
+		if rec := serveWith(h, httptest.NewRequest(http.MethodGet, "/api/books/"+id, nil)); rec.Code != http.StatusNotFound {
+			t.Errorf("book %s: expected 404 after batch delete, got %d", id, rec.Code)
+		}
+	}
+
+	// уже удалённые мягко книги удаляются безвозвратно только с hard=true
+	rec = serveWith(h, httptest.NewRequest(http.MethodDelete, "/api/books?ids=1,2&hard=true", nil))
+	resp = BatchDeleteResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf(errDecodeFmt, err)
+	}
+	if !reflect.DeepEqual(resp.Deleted, []int{1, 2}) || len(resp.NotFound) != 0 {
+		t.Errorf("expected hard delete of [1 2], got %+v", resp)
+	}
+
+	for _, query := range []string{"", "?ids=", "?ids=1,x"} {
+		if rec := serveWith(h, httptest.NewRequest(http.MethodDelete, "/api/books"+query, nil)); rec.Code != http.StatusBadRequest {
+			t.Errorf("DELETE /api/books%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+}
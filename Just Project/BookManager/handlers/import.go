@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"thirdproject/models"
+)
+
+// maxImportSize ограничивает размер загружаемого CSV
+const maxImportSize = 10 << 20 // 10 МБ
+
+// importColumns — колонки CSV-импорта в порядке по умолчанию
+// (когда в файле нет строки заголовка)
+var importColumns = []string{"title", "author", "year", "isbn"}
+
+// ImportBooks   POST /api/books/import
+// Создаёт книги из CSV с колонками title,author,year,isbn. Файл передаётся
+// телом запроса (text/csv) или полем file в multipart/form-data.
+// Строка заголовка необязательна; с ней колонки могут идти в любом порядке,
+// а лишние (например, id из экспорта) игнорируются. Ошибочные строки
+// попадают в errors с номером записи без учёта заголовка (index, с 0)
+// и номером строки файла (line, с 1), остальные создаются.
+func (h *Handler) ImportBooks(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	src, err := csvSource(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer src.Close()
+
+	cr := csv.NewReader(src)
+	cr.FieldsPerRecord = -1 // число колонок проверяем сами, построчно
+	cr.TrimLeadingSpace = true
+
+	var (
+		books     []models.Book
+		positions []int
+		lines     []int
+		errs      []models.ItemError
+		columns   map[string]int // имя колонки → индекс в строке
+		row       int            // номер записи без учёта заголовка
+	)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			errs = append(errs, models.ItemError{Index: row, Line: parseErr.StartLine, Error: "некорректная строка CSV"})
+			row++
+			continue
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "не удалось прочитать CSV")
+			return
+		}
+		// FieldPos допустим только после успешного Read
+		line, _ := cr.FieldPos(0)
+
+		if columns == nil {
+			var isHeader bool
+			columns, isHeader = csvColumns(record)
+			if isHeader {
+				continue
+			}
+		}
+
+		b, err := bookFromRecord(record, columns)
+		if err != nil {
+			errs = append(errs, models.ItemError{Index: row, Line: line, Error: err.Error()})
+			row++
+			continue
+		}
+		books = append(books, b)
+		positions = append(positions, row)
+		lines = append(lines, line)
+		row++
+	}
+
+	if len(books) == 0 && len(errs) == 0 {
+		writeError(w, http.StatusBadRequest, "CSV не содержит книг")
+		return
+	}
+	h.createMany(w, books, positions, lines, errs)
+}
+
+// csvSource возвращает CSV из тела запроса или из поля file multipart-формы
+func csvSource(r *http.Request) (io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, errors.New("ожидается CSV-файл в поле file")
+	}
+	return file, nil
+}
+
+// csvColumns определяет колонки по первой строке. Если в ней есть title и
+// author, это заголовок; иначе используется порядок importColumns.
+func csvColumns(first []string) (columns map[string]int, isHeader bool) {
+	columns = make(map[string]int)
+	for i, name := range first {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	_, hasTitle := columns["title"]
+	_, hasAuthor := columns["author"]
+	if hasTitle && hasAuthor {
+		return columns, true
+	}
+
+	columns = make(map[string]int, len(importColumns))
+	for i, name := range importColumns {
+		columns[name] = i
+	}
+	return columns, false
+}
+
+// bookFromRecord собирает книгу из строки CSV; пустой year допускается
+// и отклоняется уже при проверке книги
+func bookFromRecord(record []string, columns map[string]int) (models.Book, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	b := models.Book{
+		Title:  field("title"),
+		Author: field("author"),
+		ISBN:   field("isbn"),
+	}
+	if y := field("year"); y != "" {
+		year, err := strconv.Atoi(y)
+		if err != nil {
+			return b, fmt.Errorf("некорректный год %q", y)
+		}
+		b.Year = year
+	}
+	return b, nil
+}
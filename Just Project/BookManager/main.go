@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,9 +10,13 @@ import (
 )
 
 func main() {
+	dev := flag.Bool("dev", false, "режим разработки: включает POST /api/books/reset")
+	flag.Parse()
+
 	// Создаём хранилище и обработчики
 	store := models.NewStore()
 	h := handlers.New(store)
+	h.Dev = *dev
 
 	mux := http.NewServeMux()
 
@@ -21,11 +26,25 @@ func main() {
 	// API маршруты:
 	//   GET    /api/books        — список всех книг
 	//   POST   /api/books        — создать книгу
+	//   DELETE /api/books?ids=1,2 — удалить несколько книг (?hard=true — безвозвратно)
+	//   POST   /api/books/bulk   — создать несколько книг из JSON-массива
+	//   GET    /api/books/export — выгрузить каталог в CSV
+	//   POST   /api/books/import — загрузить книги из CSV
+	//   GET    /api/books/search?q= — поиск по названию и автору
+	//   GET    /api/books/count  — количество книг
+	//   GET    /api/books/stats  — статистика по десятилетиям и авторам
 	//   GET    /api/books/{id}   — получить книгу по ID
 	//   PUT    /api/books/{id}   — обновить книгу по ID
-	//   DELETE /api/books/{id}   — удалить книгу по ID
-	mux.HandleFunc("/api/books", h.BooksRouter)
-	mux.HandleFunc("/api/books/", h.BooksRouter)
+	//   PATCH  /api/books/{id}   — частично обновить книгу по ID
+	//   DELETE /api/books/{id}   — удалить книгу по ID (?hard=true — безвозвратно)
+	//   POST   /api/books/{id}/rating — поставить оценку (0–5)
+	//   POST   /api/books/{id}/restore — восстановить удалённую книгу
+	//   POST   /api/books/{id}/borrow — выдать экземпляр
+	//   POST   /api/books/{id}/return — вернуть экземпляр
+	//   GET    /api/books/{id}/related — другие книги того же автора
+	//   POST   /api/books/reset  — сбросить каталог (только с --dev)
+	//   GET    /api/authors      — авторы с количеством книг
+	h.RegisterRoutes(mux)
 
 	addr := ":8080"
 	fmt.Printf("Сервер запущен: http://localhost%s\n", addr)
@@ -34,7 +53,11 @@ func main() {
 	fmt.Println("  GET    http://localhost:8080/api/books/1")
 	fmt.Println("  POST   http://localhost:8080/api/books   (body: JSON)")
 	fmt.Println("  PUT    http://localhost:8080/api/books/1 (body: JSON)")
+	fmt.Println("  PATCH  http://localhost:8080/api/books/1 (body: JSON, только изменяемые поля)")
 	fmt.Println("  DELETE http://localhost:8080/api/books/1")
+	if *dev {
+		fmt.Println("  POST   http://localhost:8080/api/books/reset (режим --dev)")
+	}
 
 	log.Fatal(http.ListenAndServe(addr, mux))
 }
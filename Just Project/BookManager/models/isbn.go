@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidISBN возвращается для строки, не являющейся корректным ISBN
+var ErrInvalidISBN = errors.New("некорректный ISBN: ожидается ISBN-10 или ISBN-13")
+
+// NormalizeISBN убирает дефисы и пробелы и проверяет контрольную цифру.
+// Возвращает ISBN из одних цифр (для ISBN-10 последней может быть "X").
+func NormalizeISBN(s string) (string, error) {
+	s = strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(s))
+	switch {
+	case len(s) == 10 && validISBN10(s):
+		return s, nil
+	case len(s) == 13 && validISBN13(s):
+		return s, nil
+	}
+	return "", ErrInvalidISBN
+}
+
+// validISBN10: сумма цифр с весами 10..1 делится на 11; "X" в конце — это 10
+func validISBN10(s string) bool {
+	sum := 0
+	for i := 0; i < 10; i++ {
+		var d int
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			d = int(c - '0')
+		case c == 'X' && i == 9:
+			d = 10
+		default:
+			return false
+		}
+		sum += d * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+// validISBN13: сумма цифр с чередующимися весами 1 и 3 делится на 10
+func validISBN13(s string) bool {
+	sum := 0
+	for i := 0; i < 13; i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
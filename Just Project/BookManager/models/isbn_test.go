@@ -0,0 +1,28 @@
+package models
+
+import "testing"
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"978-0-13-235088-4", "9780132350884", true},
+		{"9780134190440", "9780134190440", true},
+		{"0-201-61622-X", "020161622X", true},
+		{"020161622x", "020161622X", true},
+		{"0 13 235088 2", "0132350882", true},
+		{"978-0-13-235088-5", "", false}, // неверная контрольная цифра
+		{"0-201-61622-1", "", false},
+		{"X201616220", "", false},
+		{"12345", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeISBN(tt.in)
+		if got != tt.want || (err == nil) != tt.ok {
+			t.Errorf("NormalizeISBN(%q) = %q, %v; want %q, ok=%v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
@@ -1,13 +1,95 @@
 package models
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Ошибки хранилища
+var (
+	ErrNotFound      = errors.New("книга не найдена")
+	ErrDuplicateISBN = errors.New("книга с таким ISBN уже существует")
+	ErrDuplicateBook = errors.New("книга с таким названием и автором уже существует")
+	ErrNoCopies      = errors.New("нет доступных экземпляров")
+	ErrAllReturned   = errors.New("все экземпляры уже на месте")
+	ErrInvalidRating = fmt.Errorf("оценка должна быть в диапазоне %d–%d", MinRating, MaxRating)
+)
+
+// Допустимый диапазон оценки книги
+const (
+	MinRating = 0
+	MaxRating = 5
+)
 
 // Book представляет книгу в нашем хранилище
 type Book struct {
-	ID     int    `json:"id"`
-	Title  string `json:"title"`
-	Author string `json:"author"`
-	Year   int    `json:"year"`
+	ID       int      `json:"id"`
+	Title    string   `json:"title"`
+	Author   string   `json:"author"`
+	Year     int      `json:"year"`
+	ISBN     string   `json:"isbn,omitempty"` // ISBN-10 или ISBN-13, хранится без дефисов
+	Genres   []string `json:"genres,omitempty"`
+	CoverURL string   `json:"cover_url,omitempty"` // ссылка на обложку, только http(s)
+	Rating   float64  `json:"rating"`              // средняя оценка читателей, 0 — оценок нет
+
+	TotalCopies     int `json:"total_copies"`     // сколько экземпляров в библиотеке
+	AvailableCopies int `json:"available_copies"` // сколько не выдано; меняется через Borrow/Return
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Удалённая книга остаётся в хранилище, но скрыта из выборок до Restore
+	Deleted   bool       `json:"deleted,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
+	ratingCount int // сколько оценок учтено в Rating
+}
+
+// MinYear — самый ранний допустимый год издания (печатный станок Гутенберга)
+const MinYear = 1450
+
+// MaxYear возвращает самый поздний допустимый год: следующий календарный,
+// чтобы можно было заносить анонсированные книги
+func MaxYear() int {
+	return time.Now().Year() + 1
+}
+
+// Validate проверяет обязательные поля, диапазон года, число экземпляров,
+// формат ISBN и ссылку на обложку (если заданы)
+func (b Book) Validate() error {
+	if strings.TrimSpace(b.Title) == "" || strings.TrimSpace(b.Author) == "" {
+		return errors.New("поля title и author обязательны")
+	}
+	if b.Year < MinYear || b.Year > MaxYear() {
+		return fmt.Errorf("поле year должно быть в диапазоне %d–%d", MinYear, MaxYear())
+	}
+	if b.TotalCopies < 0 {
+		return errors.New("поле total_copies не может быть отрицательным")
+	}
+	if b.ISBN != "" {
+		if _, err := NormalizeISBN(b.ISBN); err != nil {
+			return err
+		}
+	}
+	if b.CoverURL != "" && !validCoverURL(b.CoverURL) {
+		return errors.New("поле cover_url должно быть абсолютной ссылкой http или https")
+	}
+	return nil
+}
+
+// validCoverURL допускает только абсолютные http(s)-ссылки с хостом
+func validCoverURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
 }
 
 // Store — потокобезопасное in-memory хранилище книг
@@ -15,77 +97,693 @@ type Store struct {
 	mu     sync.RWMutex
 	books  map[int]Book
 	nextID int
+	now    func() time.Time // источник времени; подменяется в тестах
 }
 
 // NewStore создаёт новое хранилище с тестовыми данными
 func NewStore() *Store {
-	s := &Store{
-		books:  make(map[int]Book),
-		nextID: 1,
-	}
+	s := &Store{now: time.Now}
+	s.seed()
+	return s
+}
 
-	// Добавим несколько книг по умолчанию
-	s.books[1] = Book{ID: 1, Title: "The Go Programming Language", Author: "Alan A. A. Donovan", Year: 2015}
-	s.books[2] = Book{ID: 2, Title: "Clean Code", Author: "Robert C. Martin", Year: 2008}
-	s.books[3] = Book{ID: 3, Title: "The Pragmatic Programmer", Author: "Andrew Hunt", Year: 1999}
+// seed заменяет содержимое хранилища книгами по умолчанию.
+// Вызывается под блокировкой s.mu (или до того, как Store стал доступен).
+func (s *Store) seed() {
+	now := s.now()
+	s.books = map[int]Book{
+		1: {ID: 1, Title: "The Go Programming Language", Author: "Alan A. A. Donovan", Year: 2015, ISBN: "9780134190440", Genres: []string{"programming", "go"}, TotalCopies: 3},
+		2: {ID: 2, Title: "Clean Code", Author: "Robert C. Martin", Year: 2008, ISBN: "9780132350884", Genres: []string{"programming", "craftsmanship"}, TotalCopies: 2},
+		3: {ID: 3, Title: "The Pragmatic Programmer", Author: "Andrew Hunt", Year: 1999, ISBN: "9780201616224", Genres: []string{"programming", "craftsmanship"}, TotalCopies: 1},
+	}
+	for id, b := range s.books {
+		b.CreatedAt, b.UpdatedAt = now, now
+		b.AvailableCopies = b.TotalCopies
+		s.books[id] = b
+	}
 	s.nextID = 4
+}
 
-	return s
+// Reset удаляет все книги (в том числе помеченные удалёнными) и заново
+// загружает книги по умолчанию; ID снова начинаются с 4
+func (s *Store) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seed()
 }
 
-// GetAll возвращает все книги
+// GetAll возвращает все неудалённые книги
 func (s *Store) GetAll() []Book {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	list := make([]Book, 0, len(s.books))
 	for _, b := range s.books {
-		list = append(list, b)
+		if !b.Deleted {
+			list = append(list, b)
+		}
 	}
 	return list
 }
 
-// GetByID возвращает книгу по ID, или false если не найдена
+// live возвращает книгу, если она есть и не удалена.
+// Вызывается под блокировкой s.mu.
+func (s *Store) live(id int) (Book, bool) {
+	b, ok := s.books[id]
+	if !ok || b.Deleted {
+		return Book{}, false
+	}
+	return b, true
+}
+
+// Exists ищет неудалённую книгу с тем же названием и автором без учёта
+// регистра и лишних пробелов. Возвращает её ID, или false если такой нет.
+func (s *Store) Exists(title, author string) (int, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.exists(title, author)
+}
+
+// exists — Exists без блокировки. Вызывается под блокировкой s.mu.
+func (s *Store) exists(title, author string) (int, bool) {
+	title, author = normalizeText(title), normalizeText(author)
+	for _, b := range s.books {
+		if !b.Deleted && normalizeText(b.Title) == title && normalizeText(b.Author) == author {
+			return b.ID, true
+		}
+	}
+	return 0, false
+}
+
+// normalizeText приводит строку к нижнему регистру и схлопывает пробелы
+func normalizeText(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// Count возвращает количество неудалённых книг
+func (s *Store) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := 0
+	for _, b := range s.books {
+		if !b.Deleted {
+			n++
+		}
+	}
+	return n
+}
+
+// AuthorCount — автор и число его книг
+type AuthorCount struct {
+	Author string `json:"author"`
+	Count  int    `json:"count"`
+}
+
+// Authors возвращает уникальных авторов неудалённых книг с числом книг,
+// отсортированных по имени. Написания, отличающиеся только регистром или
+// пробелами, считаются одним автором; показывается написание из книги
+// с меньшим ID.
+func (s *Store) Authors() []AuthorCount {
+	return countAuthors(s.Find(BookFilter{}))
+}
+
+// countAuthors группирует книги по автору (см. Authors) и сортирует
+// результат по имени
+func countAuthors(books []Book) []AuthorCount {
+	byKey := make(map[string]*AuthorCount)
+	for _, b := range books {
+		key := normalizeText(b.Author)
+		if a, ok := byKey[key]; ok {
+			a.Count++
+			continue
+		}
+		byKey[key] = &AuthorCount{Author: strings.TrimSpace(b.Author), Count: 1}
+	}
+
+	list := make([]AuthorCount, 0, len(byKey))
+	for _, a := range byKey {
+		list = append(list, *a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return strings.ToLower(list[i].Author) < strings.ToLower(list[j].Author)
+	})
+	return list
+}
+
+// BookFilter задаёт условия отбора книг для Find.
+// Пустые поля (и Year == 0) не ограничивают выборку.
+type BookFilter struct {
+	Author string // подстрока автора, без учёта регистра
+	Title  string // подстрока названия, без учёта регистра
+	Year   int    // точное совпадение года
+	Genre  string // один из жанров книги, без учёта регистра
+
+	IncludeDeleted bool // включать удалённые книги
+}
+
+// Match сообщает, подходит ли книга под фильтр
+func (f BookFilter) Match(b Book) bool {
+	if b.Deleted && !f.IncludeDeleted {
+		return false
+	}
+	if f.Author != "" && !containsFold(b.Author, f.Author) {
+		return false
+	}
+	if f.Title != "" && !containsFold(b.Title, f.Title) {
+		return false
+	}
+	if f.Year != 0 && b.Year != f.Year {
+		return false
+	}
+	if f.Genre != "" && !hasGenre(b, f.Genre) {
+		return false
+	}
+	return true
+}
+
+// hasGenre сообщает, есть ли у книги жанр genre (без учёта регистра)
+func hasGenre(b Book, genre string) bool {
+	for _, g := range b.Genres {
+		if strings.EqualFold(g, genre) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanGenres убирает пробелы по краям, пустые строки и повторы
+func cleanGenres(genres []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, g := range genres {
+		g = strings.TrimSpace(g)
+		key := strings.ToLower(g)
+		if g == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, g)
+	}
+	return out
+}
+
+// containsFold — strings.Contains без учёта регистра
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// Find возвращает книги, подходящие под фильтр, упорядоченные по ID
+func (s *Store) Find(f BookFilter) []Book {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list := make([]Book, 0, len(s.books))
+	for _, b := range s.books {
+		if f.Match(b) {
+			list = append(list, b)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// bookLess сравнивает книги по одному полю; используется SortBooks
+var bookLess = map[string]func(a, b Book) bool{
+	"id":     func(a, b Book) bool { return a.ID < b.ID },
+	"title":  func(a, b Book) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) },
+	"author": func(a, b Book) bool { return strings.ToLower(a.Author) < strings.ToLower(b.Author) },
+	"year":   func(a, b Book) bool { return a.Year < b.Year },
+}
+
+// SortBooks сортирует книги по полю key: id, title, author или year.
+// Префикс "-" означает обратный порядок ("-year" — сначала новые).
+// Книги с равным значением поля остаются упорядочены по ID.
+func SortBooks(books []Book, key string) error {
+	field, desc := strings.CutPrefix(key, "-")
+	less, ok := bookLess[field]
+	if !ok {
+		return fmt.Errorf("неизвестное поле сортировки %q", field)
+	}
+	sort.SliceStable(books, func(i, j int) bool {
+		if desc {
+			return less(books[j], books[i])
+		}
+		return less(books[i], books[j])
+	})
+	return nil
+}
+
+// RankByMatch оставляет книги, у которых q (без учёта регистра) входит
+// в название или автора, и упорядочивает их по релевантности: чем раньше
+// совпадение, тем выше; при равной позиции совпадение в названии важнее,
+// чем в авторе, дальше — по ID.
+func RankByMatch(books []Book, q string) []Book {
+	q = strings.ToLower(strings.TrimSpace(q))
+	if q == "" {
+		return nil
+	}
+
+	type ranked struct {
+		book  Book
+		pos   int // позиция совпадения в рунах
+		field int // 0 — название, 1 — автор
+	}
+	var matches []ranked
+	for _, b := range books {
+		best := ranked{book: b, pos: -1}
+		for field, text := range []string{b.Title, b.Author} {
+			pos := matchPos(text, q)
+			if pos >= 0 && (best.pos < 0 || pos < best.pos) {
+				best.pos, best.field = pos, field
+			}
+		}
+		if best.pos >= 0 {
+			matches = append(matches, best)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.pos != b.pos {
+			return a.pos < b.pos
+		}
+		if a.field != b.field {
+			return a.field < b.field
+		}
+		return a.book.ID < b.book.ID
+	})
+
+	result := make([]Book, len(matches))
+	for i, m := range matches {
+		result[i] = m.book
+	}
+	return result
+}
+
+// matchPos возвращает позицию (в рунах) первого вхождения q в text без
+// учёта регистра, или -1; q уже в нижнем регистре
+func matchPos(text, q string) int {
+	lower := strings.ToLower(text)
+	i := strings.Index(lower, q)
+	if i < 0 {
+		return -1
+	}
+	return utf8.RuneCountInString(lower[:i])
+}
+
+// GetByID возвращает книгу по ID, или false если она не найдена или удалена
 func (s *Store) GetByID(id int) (Book, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	b, ok := s.books[id]
-	return b, ok
+	return s.live(id)
 }
 
-// Create добавляет новую книгу и возвращает её с присвоенным ID
-func (s *Store) Create(b Book) Book {
+// Related возвращает до limit других неудалённых книг того же автора
+// (без учёта регистра и лишних пробелов) по порядку ID.
+// Если книги id нет, возвращает ErrNotFound.
+func (s *Store) Related(id, limit int) ([]Book, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	book, ok := s.live(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	author := normalizeText(book.Author)
+
+	list := []Book{}
+	for _, b := range s.books {
+		if b.ID != id && !b.Deleted && normalizeText(b.Author) == author {
+			list = append(list, b)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// GetByISBN ищет неудалённую книгу по ISBN (с дефисами или без),
+// или false если не найдена
+func (s *Store) GetByISBN(isbn string) (Book, bool) {
+	isbn, err := NormalizeISBN(isbn)
+	if err != nil {
+		return Book{}, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, b := range s.books {
+		if !b.Deleted && b.ISBN == isbn {
+			return b, true
+		}
+	}
+	return Book{}, false
+}
+
+// prepare проверяет книгу перед сохранением под id (0 — новая книга):
+// валидирует поля, сохраняет текущий рейтинг и отметку об удалении,
+// пересчитывает доступные экземпляры, ставит время создания (для новой
+// книги) или сохраняет прежнее, обновляет UpdatedAt, чистит список жанров,
+// нормализует ISBN и проверяет его уникальность (включая удалённые книги,
+// чтобы Restore не создал дубликат).
+// Вызывается под блокировкой s.mu.
+func (s *Store) prepare(b *Book, id int) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	// Рейтинг меняется только через Rate, удаление — через SoftDelete/Restore
+	old := s.books[id]
+	b.Rating, b.ratingCount = old.Rating, old.ratingCount
+	b.Deleted, b.DeletedAt = old.Deleted, old.DeletedAt
+	// Выданные экземпляры остаются выданными при изменении total_copies
+	borrowed := old.TotalCopies - old.AvailableCopies
+	if b.TotalCopies < borrowed {
+		return fmt.Errorf("поле total_copies не может быть меньше числа выданных экземпляров (%d)", borrowed)
+	}
+	b.AvailableCopies = b.TotalCopies - borrowed
+	now := s.now()
+	if id == 0 {
+		b.CreatedAt = now
+	} else {
+		b.CreatedAt = old.CreatedAt
+	}
+	b.UpdatedAt = now
+	b.Genres = cleanGenres(b.Genres)
+	if b.ISBN == "" {
+		return nil
+	}
+	b.ISBN, _ = NormalizeISBN(b.ISBN)
+	for _, other := range s.books {
+		if other.ID != id && other.ISBN == b.ISBN {
+			return ErrDuplicateISBN
+		}
+	}
+	return nil
+}
+
+// Create проверяет и добавляет новую книгу, возвращает её с присвоенным ID
+func (s *Store) Create(b Book) (Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.create(b)
+}
+
+// CreateUnique добавляет книгу, только если нет неудалённой книги с тем же
+// названием и автором (см. Exists). Проверка и вставка идут под одной
+// блокировкой. При дубликате возвращает ID существующей книги
+// и ErrDuplicateBook.
+func (s *Store) CreateUnique(b Book) (Book, int, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if id, ok := s.exists(b.Title, b.Author); ok {
+		return Book{}, id, ErrDuplicateBook
+	}
+	created, err := s.create(b)
+	return created, created.ID, err
+}
+
+// create проверяет книгу и сохраняет её под новым ID.
+// Вызывается под блокировкой s.mu.
+func (s *Store) create(b Book) (Book, error) {
+	if err := s.prepare(&b, 0); err != nil {
+		return Book{}, err
+	}
 	b.ID = s.nextID
 	s.nextID++
 	s.books[b.ID] = b
-	return b
+	return b, nil
 }
 
-// Update обновляет существующую книгу, возвращает false если не найдена
-func (s *Store) Update(id int, updated Book) (Book, bool) {
+// ItemError описывает ошибку для одного элемента пакетной операции
+type ItemError struct {
+	Index int    `json:"index"`          // позиция элемента во входном списке (с 0)
+	Line  int    `json:"line,omitempty"` // номер строки файла (с 1), только для импорта CSV
+	Error string `json:"error"`
+}
+
+// CreateMany добавляет все корректные книги под одной блокировкой.
+// Некорректные пропускаются и попадают в список ошибок со своей позицией.
+func (s *Store) CreateMany(books []Book) ([]Book, []ItemError) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.books[id]; !ok {
-		return Book{}, false
+	created := make([]Book, 0, len(books))
+	var errs []ItemError
+	for i, b := range books {
+		if err := s.prepare(&b, 0); err != nil {
+			errs = append(errs, ItemError{Index: i, Error: err.Error()})
+			continue
+		}
+		b.ID = s.nextID
+		s.nextID++
+		s.books[b.ID] = b
+		created = append(created, b)
+	}
+	return created, errs
+}
+
+// Update проверяет и полностью заменяет существующую книгу.
+// Возвращает ErrNotFound, если книги нет или она удалена.
+func (s *Store) Update(id int, updated Book) (Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.live(id); !ok {
+		return Book{}, ErrNotFound
+	}
+	if err := s.prepare(&updated, id); err != nil {
+		return Book{}, err
 	}
 	updated.ID = id
 	s.books[id] = updated
-	return updated, true
+	return updated, nil
 }
 
-// Delete удаляет книгу по ID, возвращает false если не найдена
+// Patch обновляет только переданные поля книги
+// (title, author, year, isbn, genres, total_copies, cover_url).
+// Значения проверяются по типу; при ошибке книга не меняется.
+// Возвращает ErrNotFound, если книги нет.
+func (s *Store) Patch(id int, fields map[string]any) (Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.live(id)
+	if !ok {
+		return Book{}, ErrNotFound
+	}
+	if err := applyPatch(&b, fields); err != nil {
+		return Book{}, err
+	}
+	if err := s.prepare(&b, id); err != nil {
+		return Book{}, err
+	}
+	s.books[id] = b
+	return b, nil
+}
+
+// applyPatch записывает значения из fields в b
+func applyPatch(b *Book, fields map[string]any) error {
+	for name, v := range fields {
+		switch name {
+		case "title", "author":
+			str, ok := v.(string)
+			if !ok || strings.TrimSpace(str) == "" {
+				return fmt.Errorf("поле %s должно быть непустой строкой", name)
+			}
+			if name == "title" {
+				b.Title = str
+			} else {
+				b.Author = str
+			}
+		case "year":
+			// encoding/json декодирует числа в map[string]any как float64
+			f, ok := v.(float64)
+			if !ok || f != float64(int(f)) {
+				return errors.New("поле year должно быть целым числом")
+			}
+			b.Year = int(f)
+		case "total_copies":
+			f, ok := v.(float64)
+			if !ok || f != float64(int(f)) {
+				return errors.New("поле total_copies должно быть целым числом")
+			}
+			b.TotalCopies = int(f)
+		case "cover_url":
+			str, ok := v.(string)
+			if !ok {
+				return errors.New("поле cover_url должно быть строкой")
+			}
+			b.CoverURL = str
+		case "isbn":
+			str, ok := v.(string)
+			if !ok {
+				return errors.New("поле isbn должно быть строкой")
+			}
+			b.ISBN = str
+		case "genres":
+			list, ok := v.([]any)
+			if !ok {
+				return errors.New("поле genres должно быть массивом строк")
+			}
+			genres := make([]string, 0, len(list))
+			for _, g := range list {
+				str, ok := g.(string)
+				if !ok {
+					return errors.New("поле genres должно быть массивом строк")
+				}
+				genres = append(genres, str)
+			}
+			b.Genres = genres
+		case "id":
+			return errors.New("поле id нельзя изменить")
+		default:
+			return fmt.Errorf("неизвестное поле %q", name)
+		}
+	}
+	return nil
+}
+
+// Rate учитывает новую оценку книги и пересчитывает среднее.
+// Возвращает ErrInvalidRating для оценки вне диапазона и ErrNotFound,
+// если книги нет.
+func (s *Store) Rate(id int, score float64) (Book, error) {
+	if score < MinRating || score > MaxRating {
+		return Book{}, ErrInvalidRating
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.live(id)
+	if !ok {
+		return Book{}, ErrNotFound
+	}
+	b.Rating = (b.Rating*float64(b.ratingCount) + score) / float64(b.ratingCount+1)
+	b.ratingCount++
+	s.books[id] = b
+	return b, nil
+}
+
+// Borrow выдаёт один экземпляр книги. Возвращает ErrNoCopies, если все
+// экземпляры выданы, и ErrNotFound, если книги нет.
+func (s *Store) Borrow(id int) (Book, error) {
+	return s.adjustCopies(id, -1)
+}
+
+// Return возвращает один экземпляр книги. Возвращает ErrAllReturned, если
+// ни один экземпляр не выдан, и ErrNotFound, если книги нет.
+func (s *Store) Return(id int) (Book, error) {
+	return s.adjustCopies(id, +1)
+}
+
+// adjustCopies меняет число доступных экземпляров на delta в пределах
+// 0..TotalCopies
+func (s *Store) adjustCopies(id, delta int) (Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.live(id)
+	if !ok {
+		return Book{}, ErrNotFound
+	}
+	n := b.AvailableCopies + delta
+	switch {
+	case n < 0:
+		return Book{}, ErrNoCopies
+	case n > b.TotalCopies:
+		return Book{}, ErrAllReturned
+	}
+	b.AvailableCopies = n
+	s.books[id] = b
+	return b, nil
+}
+
+// SoftDelete помечает книгу удалённой: она пропадает из выборок, но может
+// быть восстановлена через Restore. Возвращает ErrNotFound, если книги нет
+// или она уже удалена.
+func (s *Store) SoftDelete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.remove(id, false) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Restore снимает отметку об удалении. Для неудалённой книги ничего не
+// меняет. Возвращает ErrNotFound, если книги нет.
+func (s *Store) Restore(id int) (Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.books[id]
+	if !ok {
+		return Book{}, ErrNotFound
+	}
+	b.Deleted, b.DeletedAt = false, nil
+	s.books[id] = b
+	return b, nil
+}
+
+// Delete безвозвратно удаляет книгу по ID (в том числе помеченную
+// удалённой), возвращает false если не найдена
 func (s *Store) Delete(id int) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.books[id]; !ok {
+	return s.remove(id, true)
+}
+
+// DeleteMany удаляет несколько книг под одной блокировкой: помечает
+// удалёнными или, с hard, удаляет безвозвратно — по тем же правилам, что
+// SoftDelete и Delete. Возвращает ID удалённых книг и ID, которых не нашлось,
+// в порядке ids; повторы учитываются один раз.
+func (s *Store) DeleteMany(ids []int, hard bool) (deleted, notFound []int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted, notFound = []int{}, []int{}
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if s.remove(id, hard) {
+			deleted = append(deleted, id)
+		} else {
+			notFound = append(notFound, id)
+		}
+	}
+	return deleted, notFound
+}
+
+// remove удаляет книгу: hard — безвозвратно (в том числе помеченную
+// удалённой), иначе помечает неудалённую книгу удалённой. Возвращает
+// false, если удалять нечего. Вызывается под блокировкой s.mu.
+func (s *Store) remove(id int, hard bool) bool {
+	if hard {
+		if _, ok := s.books[id]; !ok {
+			return false
+		}
+		delete(s.books, id)
+		return true
+	}
+
+	b, ok := s.live(id)
+	if !ok {
 		return false
 	}
-	delete(s.books, id)
+	now := s.now()
+	b.Deleted, b.DeletedAt = true, &now
+	s.books[id] = b
 	return true
 }
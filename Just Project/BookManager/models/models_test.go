@@ -0,0 +1,571 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFindByAuthor(t *testing.T) {
+	s := NewStore()
+	if _, err := s.Create(Book{Title: "Clean Architecture", Author: "Robert C. Martin", Year: 2017}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	got := s.Find(BookFilter{Author: "martin"})
+	if len(got) != 2 {
+		t.Fatalf("expected 2 books by Martin, got %d: %+v", len(got), got)
+	}
+	for _, b := range got {
+		if b.Author != "Robert C. Martin" {
+			t.Errorf("unexpected author %q", b.Author)
+		}
+	}
+	if got[0].ID > got[1].ID {
+		t.Errorf("expected books ordered by ID, got %d before %d", got[0].ID, got[1].ID)
+	}
+}
+
+func TestFindByTitleAndYear(t *testing.T) {
+	s := NewStore()
+
+	if got := s.Find(BookFilter{Title: "PRAGMATIC"}); len(got) != 1 || got[0].Year != 1999 {
+		t.Errorf("expected The Pragmatic Programmer, got %+v", got)
+	}
+	if got := s.Find(BookFilter{Year: 2015}); len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("expected book 1 for year 2015, got %+v", got)
+	}
+	if got := s.Find(BookFilter{Title: "code", Year: 1999}); len(got) != 0 {
+		t.Errorf("expected no books, got %+v", got)
+	}
+	if got := s.Find(BookFilter{}); len(got) != 3 {
+		t.Errorf("expected empty filter to match all 3 books, got %d", len(got))
+	}
+}
+
+func TestSortBooks(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []int // ожидаемый порядок ID
+	}{
+		{"year", []int{3, 2, 1}},
+		{"-year", []int{1, 2, 3}},
+		{"title", []int{2, 1, 3}},
+		{"-title", []int{3, 1, 2}},
+		{"author", []int{1, 3, 2}},
+		{"-id", []int{3, 2, 1}},
+	}
+	for _, tt := range tests {
+		books := NewStore().Find(BookFilter{})
+		if err := SortBooks(books, tt.key); err != nil {
+			t.Fatalf("SortBooks(%q): %v", tt.key, err)
+		}
+		for i, id := range tt.want {
+			if books[i].ID != id {
+				t.Errorf("sort=%s: expected IDs %v, got %+v", tt.key, tt.want, books)
+				break
+			}
+		}
+	}
+}
+
+func TestSortBooksUnknownField(t *testing.T) {
+	if err := SortBooks(NewStore().Find(BookFilter{}), "isbn"); err == nil {
+		t.Error("expected error for unknown sort field")
+	}
+}
+
+func TestPatchYearOnly(t *testing.T) {
+	s := NewStore()
+	before, _ := s.GetByID(2)
+
+	got, err := s.Patch(2, map[string]any{"year": float64(2009)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Year != 2009 || got.Title != before.Title || got.Author != before.Author {
+		t.Errorf("expected only year to change, got %+v (was %+v)", got, before)
+	}
+}
+
+func TestPatchInvalid(t *testing.T) {
+	s := NewStore()
+	before, _ := s.GetByID(1)
+
+	for _, fields := range []map[string]any{
+		{"year": "2020"},
+		{"year": 2020.5},
+		{"title": 42},
+		{"author": ""},
+		{"pages": float64(300)},
+	} {
+		if _, err := s.Patch(1, fields); err == nil {
+			t.Errorf("expected error for %v", fields)
+		}
+	}
+	if after, _ := s.GetByID(1); !reflect.DeepEqual(after, before) {
+		t.Errorf("expected book unchanged after failed patches, got %+v", after)
+	}
+
+	if _, err := s.Patch(99, map[string]any{"year": float64(2000)}); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestValidateYear(t *testing.T) {
+	tests := []struct {
+		year int
+		ok   bool
+	}{
+		{0, false},
+		{1449, false},
+		{MinYear, true},
+		{2015, true},
+		{MaxYear(), true},
+		{MaxYear() + 1, false},
+	}
+	for _, tt := range tests {
+		err := Book{Title: "T", Author: "A", Year: tt.year}.Validate()
+		if (err == nil) != tt.ok {
+			t.Errorf("year %d: expected ok=%v, got err=%v", tt.year, tt.ok, err)
+		}
+	}
+}
+
+func TestValidateRequiresTitleAndAuthor(t *testing.T) {
+	if err := (Book{Author: "A", Year: 2000}).Validate(); err == nil {
+		t.Error("expected error for missing title")
+	}
+	if err := (Book{Title: "T", Author: " ", Year: 2000}).Validate(); err == nil {
+		t.Error("expected error for blank author")
+	}
+}
+
+func TestCreateMany(t *testing.T) {
+	s := NewStore()
+
+	created, errs := s.CreateMany([]Book{
+		{Title: "Refactoring", Author: "Martin Fowler", Year: 1999},
+		{Title: "", Author: "Nobody", Year: 2000},
+		{Title: "Domain-Driven Design", Author: "Eric Evans", Year: 2003},
+		{Title: "Future", Author: "Someone", Year: 9999},
+	})
+
+	if len(created) != 2 || created[0].ID != 4 || created[1].ID != 5 {
+		t.Errorf("expected books 4 and 5 created, got %+v", created)
+	}
+	if len(errs) != 2 || errs[0].Index != 1 || errs[1].Index != 3 {
+		t.Errorf("expected errors for items 1 and 3, got %+v", errs)
+	}
+	if n := len(s.GetAll()); n != 5 {
+		t.Errorf("expected 5 books in store, got %d", n)
+	}
+}
+
+func TestCreateWithISBN(t *testing.T) {
+	s := NewStore()
+
+	b, err := s.Create(Book{Title: "Refactoring", Author: "Martin Fowler", Year: 1999, ISBN: "0-201-48567-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if b.ISBN != "0201485672" {
+		t.Errorf("expected normalized ISBN 0201485672, got %q", b.ISBN)
+	}
+	if got, ok := s.GetByISBN("020-148-5672"); !ok || got.ID != b.ID {
+		t.Errorf("expected GetByISBN to find book %d, got %+v (ok=%v)", b.ID, got, ok)
+	}
+}
+
+func TestCreateInvalidISBN(t *testing.T) {
+	_, err := NewStore().Create(Book{Title: "T", Author: "A", Year: 2000, ISBN: "123-456"})
+	if err != ErrInvalidISBN {
+		t.Errorf("expected ErrInvalidISBN, got %v", err)
+	}
+}
+
+func TestDuplicateISBN(t *testing.T) {
+	s := NewStore()
+
+	_, err := s.Create(Book{Title: "Clean Code (copy)", Author: "Robert C. Martin", Year: 2008, ISBN: "978-0-13-235088-4"})
+	if err != ErrDuplicateISBN {
+		t.Errorf("Create: expected ErrDuplicateISBN, got %v", err)
+	}
+
+	// Книга может сохранить собственный ISBN, но не занять чужой
+	if _, err := s.Patch(2, map[string]any{"isbn": "9780132350884"}); err != nil {
+		t.Errorf("Patch with own ISBN: unexpected error %v", err)
+	}
+	if _, err := s.Patch(2, map[string]any{"isbn": "9780134190440"}); err != ErrDuplicateISBN {
+		t.Errorf("Patch: expected ErrDuplicateISBN, got %v", err)
+	}
+}
+
+func TestFindByGenre(t *testing.T) {
+	s := NewStore()
+	if _, err := s.Create(Book{Title: "Dune", Author: "Frank Herbert", Year: 1965, Genres: []string{" Sci-Fi ", "", "sci-fi"}}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := s.Create(Book{Title: "Untagged", Author: "Anon", Year: 2000}); err != nil {
+		t.Fatalf("Create without genres: %v", err)
+	}
+
+	got := s.Find(BookFilter{Genre: "SCI-FI"})
+	if len(got) != 1 || got[0].Title != "Dune" {
+		t.Fatalf("expected only Dune, got %+v", got)
+	}
+	if !reflect.DeepEqual(got[0].Genres, []string{"Sci-Fi"}) {
+		t.Errorf("expected cleaned genres [Sci-Fi], got %q", got[0].Genres)
+	}
+	if got := s.Find(BookFilter{Genre: "craftsmanship"}); len(got) != 2 {
+		t.Errorf("expected 2 craftsmanship books, got %+v", got)
+	}
+}
+
+func TestRateAverages(t *testing.T) {
+	s := NewStore()
+
+	for _, score := range []float64{5, 4, 3} {
+		if _, err := s.Rate(1, score); err != nil {
+			t.Fatalf("Rate(%v): %v", score, err)
+		}
+	}
+	b, _ := s.GetByID(1)
+	if b.Rating != 4 {
+		t.Errorf("expected average 4, got %v", b.Rating)
+	}
+
+	// Полное обновление не сбрасывает рейтинг
+	b.Title = "The Go Programming Language (2nd ed.)"
+	b.Rating = 1
+	updated, err := s.Update(1, b)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Rating != 4 {
+		t.Errorf("expected rating kept at 4 after update, got %v", updated.Rating)
+	}
+	if b, _ := s.Rate(1, 0); b.Rating != 3 {
+		t.Errorf("expected average 3 after fourth rating, got %v", b.Rating)
+	}
+}
+
+func TestRateErrors(t *testing.T) {
+	s := NewStore()
+	for _, score := range []float64{-1, 5.5} {
+		if _, err := s.Rate(1, score); err != ErrInvalidRating {
+			t.Errorf("Rate(%v): expected ErrInvalidRating, got %v", score, err)
+		}
+	}
+	if _, err := s.Rate(99, 3); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRankByMatch(t *testing.T) {
+	books := []Book{
+		{ID: 1, Title: "The Go Programming Language", Author: "Alan A. A. Donovan"},
+		{ID: 2, Title: "Go in Action", Author: "William Kennedy"},
+		{ID: 3, Title: "Learning Go", Author: "Jon Bodner"},
+		{ID: 4, Title: "Clean Code", Author: "Robert C. Martin"},
+		{ID: 5, Title: "Concurrency", Author: "Gopher Smith"},
+	}
+
+	got := RankByMatch(books, "GO")
+	var ids []int
+	for _, b := range got {
+		ids = append(ids, b.ID)
+	}
+	// 2 и 5 совпадают с позиции 0 (название важнее автора), затем 1 (позиция 4), 3 (позиция 9)
+	want := []int{2, 5, 1, 3}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("expected order %v, got %v", want, ids)
+	}
+
+	if got := RankByMatch(books, "martin"); len(got) != 1 || got[0].ID != 4 {
+		t.Errorf("expected only Clean Code for author match, got %+v", got)
+	}
+	if got := RankByMatch(books, "  "); got != nil {
+		t.Errorf("expected nil for empty query, got %+v", got)
+	}
+}
+
+func TestCount(t *testing.T) {
+	s := NewStore()
+	if n := s.Count(); n != 3 {
+		t.Fatalf("expected 3 seeded books, got %d", n)
+	}
+
+	b, err := s.Create(Book{Title: "Refactoring", Author: "Martin Fowler", Year: 1999})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if n := s.Count(); n != 4 {
+		t.Errorf("expected 4 after create, got %d", n)
+	}
+
+	s.Delete(b.ID)
+	s.Delete(1)
+	if n := s.Count(); n != 2 {
+		t.Errorf("expected 2 after two deletes, got %d", n)
+	}
+}
+
+func TestExists(t *testing.T) {
+	s := NewStore()
+
+	if id, ok := s.Exists("  clean   CODE ", "robert c. martin"); !ok || id != 2 {
+		t.Errorf("expected Clean Code (2) to exist, got %d, %v", id, ok)
+	}
+	if _, ok := s.Exists("Clean Code", "Someone Else"); ok {
+		t.Error("expected no match for a different author")
+	}
+}
+
+func TestCreateUnique(t *testing.T) {
+	s := NewStore()
+
+	_, id, err := s.CreateUnique(Book{Title: "clean code", Author: "Robert C. Martin"})
+	if err != ErrDuplicateBook || id != 2 {
+		t.Fatalf("expected ErrDuplicateBook with id 2, got %d, %v", id, err)
+	}
+	before := s.Count()
+	b, id, err := s.CreateUnique(Book{Title: "Refactoring", Author: "Martin Fowler", Year: 1999})
+	if err != nil {
+		t.Fatalf("CreateUnique: %v", err)
+	}
+	if id != b.ID || s.Count() != before+1 {
+		t.Errorf("expected new book %d to be stored, got id %d and count %d", b.ID, id, s.Count())
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	s := NewStore()
+
+	if err := s.SoftDelete(2); err != nil {
+		t.Fatalf("SoftDelete: %v", err)
+	}
+	if _, ok := s.GetByID(2); ok {
+		t.Error("expected soft-deleted book to be hidden from GetByID")
+	}
+	if n := s.Count(); n != 2 {
+		t.Errorf("expected count 2, got %d", n)
+	}
+	if got := s.Find(BookFilter{}); len(got) != 2 {
+		t.Errorf("expected 2 visible books, got %+v", got)
+	}
+	all := s.Find(BookFilter{IncludeDeleted: true})
+	if len(all) != 3 || !all[1].Deleted || all[1].DeletedAt == nil {
+		t.Errorf("expected deleted book 2 with timestamp in full list, got %+v", all)
+	}
+	if _, err := s.Patch(2, map[string]any{"year": float64(2009)}); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound patching a deleted book, got %v", err)
+	}
+	if err := s.SoftDelete(2); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+
+	b, err := s.Restore(2)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if b.Deleted || b.DeletedAt != nil {
+		t.Errorf("expected restored book to be live, got %+v", b)
+	}
+	if _, ok := s.GetByID(2); !ok {
+		t.Error("expected restored book to be visible")
+	}
+	if _, err := s.Restore(99); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestTimestamps(t *testing.T) {
+	s := NewStore()
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return clock }
+
+	b, err := s.Create(Book{Title: "Refactoring", Author: "Martin Fowler", Year: 1999})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if !b.CreatedAt.Equal(clock) || !b.UpdatedAt.Equal(clock) {
+		t.Fatalf("expected both timestamps %v, got %v / %v", clock, b.CreatedAt, b.UpdatedAt)
+	}
+	created := b.CreatedAt
+
+	clock = clock.Add(time.Hour)
+	b.Year = 2018
+	b.CreatedAt = time.Time{} // клиент не может переписать время создания
+	if b, err = s.Update(b.ID, b); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if !b.CreatedAt.Equal(created) || !b.UpdatedAt.Equal(clock) {
+		t.Errorf("after Update: expected created %v, updated %v; got %v / %v", created, clock, b.CreatedAt, b.UpdatedAt)
+	}
+
+	clock = clock.Add(time.Hour)
+	if b, err = s.Patch(b.ID, map[string]any{"title": "Refactoring (2nd ed.)"}); err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	if !b.CreatedAt.Equal(created) || !b.UpdatedAt.Equal(clock) {
+		t.Errorf("after Patch: expected created %v, updated %v; got %v / %v", created, clock, b.CreatedAt, b.UpdatedAt)
+	}
+}
+
+func TestReset(t *testing.T) {
+	s := NewStore()
+	seeded := s.Find(BookFilter{})
+
+	if _, err := s.Create(Book{Title: "Refactoring", Author: "Martin Fowler", Year: 1999}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := s.Patch(1, map[string]any{"year": float64(2016)}); err != nil {
+		t.Fatalf("Patch: %v", err)
+	}
+	s.SoftDelete(2)
+	s.Delete(3)
+
+	s.Reset()
+
+	got := s.Find(BookFilter{IncludeDeleted: true})
+	if len(got) != len(seeded) {
+		t.Fatalf("expected %d books after reset, got %+v", len(seeded), got)
+	}
+	for i := range got {
+		if got[i].ID != seeded[i].ID || got[i].Title != seeded[i].Title || got[i].Year != seeded[i].Year || got[i].Deleted {
+			t.Errorf("book %d: expected %+v, got %+v", i, seeded[i], got[i])
+		}
+	}
+	if b, _ := s.Create(Book{Title: "Next", Author: "Anon", Year: 2000}); b.ID != 4 {
+		t.Errorf("expected IDs to restart at 4, got %d", b.ID)
+	}
+}
+
+func TestBorrowAndReturn(t *testing.T) {
+	s := NewStore()
+
+	// У книги 2 два экземпляра
+	for want := 1; want >= 0; want-- {
+		b, err := s.Borrow(2)
+		if err != nil {
+			t.Fatalf("Borrow: %v", err)
+		}
+		if b.AvailableCopies != want {
+			t.Errorf("expected %d available, got %d", want, b.AvailableCopies)
+		}
+	}
+	if _, err := s.Borrow(2); err != ErrNoCopies {
+		t.Errorf("expected ErrNoCopies, got %v", err)
+	}
+
+	// Нельзя оставить меньше экземпляров, чем выдано
+	if _, err := s.Patch(2, map[string]any{"total_copies": float64(1)}); err == nil {
+		t.Error("expected error reducing total_copies below borrowed count")
+	}
+	b, err := s.Patch(2, map[string]any{"total_copies": float64(3)})
+	if err != nil {
+		t.Fatalf("Patch total_copies: %v", err)
+	}
+	if b.AvailableCopies != 1 {
+		t.Errorf("expected 1 available after adding a copy, got %d", b.AvailableCopies)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.Return(2); err != nil {
+			t.Fatalf("Return: %v", err)
+		}
+	}
+	if _, err := s.Return(2); err != ErrAllReturned {
+		t.Errorf("expected ErrAllReturned, got %v", err)
+	}
+	if _, err := s.Borrow(99); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAuthors(t *testing.T) {
+	s := NewStore()
+	s.Create(Book{Title: "Clean Architecture", Author: "robert c.  martin", Year: 2017})
+	s.Create(Book{Title: "Refactoring", Author: "Martin Fowler", Year: 1999})
+	s.SoftDelete(1)
+
+	want := []AuthorCount{
+		{Author: "Andrew Hunt", Count: 1},
+		{Author: "Martin Fowler", Count: 1},
+		{Author: "Robert C. Martin", Count: 2},
+	}
+	if got := s.Authors(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestRelated(t *testing.T) {
+	s := NewStore()
+	a, _ := s.Create(Book{Title: "Clean Architecture", Author: "Robert C. Martin", Year: 2017})
+	b, _ := s.Create(Book{Title: "The Clean Coder", Author: "robert c. martin", Year: 2011})
+	c, _ := s.Create(Book{Title: "Clean Agile", Author: "Robert C. Martin", Year: 2019})
+	s.Create(Book{Title: "Refactoring", Author: "Martin Fowler", Year: 1999})
+	s.SoftDelete(c.ID)
+
+	got, err := s.Related(2, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != a.ID || got[1].ID != b.ID {
+		t.Errorf("expected books %d and %d, got %+v", a.ID, b.ID, got)
+	}
+
+	if got, _ := s.Related(2, 1); len(got) != 1 || got[0].ID != a.ID {
+		t.Errorf("expected limit 1 to keep book %d, got %+v", a.ID, got)
+	}
+	if got, _ := s.Related(1, 5); len(got) != 0 {
+		t.Errorf("expected no related books, got %+v", got)
+	}
+	if _, err := s.Related(99, 5); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestValidateCoverURL(t *testing.T) {
+	tests := []struct {
+		url string
+		ok  bool
+	}{
+		{"", true},
+		{"https://covers.openlibrary.org/b/isbn/9780134190440-M.jpg", true},
+		{"http://example.com/cover.png", true},
+		{"ftp://example.com/cover.png", false},
+		{"javascript:alert(1)", false},
+		{"/relative/cover.png", false},
+		{"https://", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		err := Book{Title: "T", Author: "A", Year: 2000, CoverURL: tt.url}.Validate()
+		if (err == nil) != tt.ok {
+			t.Errorf("cover_url %q: expected ok=%v, got err=%v", tt.url, tt.ok, err)
+		}
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	s := NewStore()
+	s.SoftDelete(2)
+
+	deleted, notFound := s.DeleteMany([]int{1, 2, 99, 1}, false)
+	if !reflect.DeepEqual(deleted, []int{1}) || !reflect.DeepEqual(notFound, []int{2, 99}) {
+		t.Errorf("soft: expected deleted [1] and not found [2 99], got %v and %v", deleted, notFound)
+	}
+	if s.Count() != 1 {
+		t.Errorf("expected 1 live book, got %d", s.Count())
+	}
+
+	deleted, notFound = s.DeleteMany([]int{2, 3, 99}, true)
+	if !reflect.DeepEqual(deleted, []int{2, 3}) || !reflect.DeepEqual(notFound, []int{99}) {
+		t.Errorf("hard: expected deleted [2 3] and not found [99], got %v and %v", deleted, notFound)
+	}
+	if _, err := s.Restore(2); err != ErrNotFound {
+		t.Errorf("expected hard-deleted book to be gone, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SearchOptions — параметры Store.Search: фильтр, поисковая строка,
+// сортировка и постраничный вывод. Нулевое значение возвращает все
+// неудалённые книги по порядку ID.
+type SearchOptions struct {
+	BookFilter
+
+	Query  string // подстрока названия или автора, без учёта регистра
+	Sort   string // поле сортировки, как в SortBooks; "" — по релевантности или по ID
+	Offset int    // сколько книг пропустить
+	Limit  int    // сколько книг вернуть; 0 — все
+}
+
+// Validate проверяет поле сортировки и параметры страницы
+func (o SearchOptions) Validate() error {
+	if o.Sort != "" {
+		field := strings.TrimPrefix(o.Sort, "-")
+		if _, ok := bookLess[field]; !ok {
+			return fmt.Errorf("неизвестное поле сортировки %q", field)
+		}
+	}
+	if o.Offset < 0 {
+		return errors.New("offset не может быть отрицательным")
+	}
+	if o.Limit < 0 {
+		return errors.New("limit не может быть отрицательным")
+	}
+	return nil
+}
+
+// Search отбирает книги по фильтру и Query, сортирует и возвращает
+// страницу [Offset, Offset+Limit). С Query книги упорядочены по
+// релевантности (см. RankByMatch), если не задан Sort; при заданном Sort
+// равные по полю книги сохраняют порядок релевантности.
+// Параметры должны пройти Validate: неизвестное поле сортировки
+// игнорируется, отрицательные Offset и Limit считаются нулём.
+func (s *Store) Search(opts SearchOptions) []Book {
+	books := s.Find(opts.BookFilter)
+	if q := strings.TrimSpace(opts.Query); q != "" {
+		books = RankByMatch(books, q)
+	}
+	if opts.Sort != "" {
+		SortBooks(books, opts.Sort)
+	}
+
+	if opts.Offset >= len(books) {
+		return []Book{}
+	}
+	if opts.Offset > 0 {
+		books = books[opts.Offset:]
+	}
+	if opts.Limit > 0 && opts.Limit < len(books) {
+		books = books[:opts.Limit]
+	}
+	return books
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+// searchStore — сид-книги (1–3) и ещё три, одна из которых удалена
+func searchStore(t *testing.T) *Store {
+	t.Helper()
+	s := NewStore()
+	for _, b := range []Book{
+		{Title: "Clean Architecture", Author: "Robert C. Martin", Year: 2017, Genres: []string{"Architecture"}}, // 4
+		{Title: "Go in Action", Author: "William Kennedy", Year: 2015, Genres: []string{"go"}},                  // 5
+		{Title: "Clean Agile", Author: "Robert C. Martin", Year: 2019},                                          // 6
+	} {
+		if _, err := s.Create(b); err != nil {
+			t.Fatalf("create %q: %v", b.Title, err)
+		}
+	}
+	s.SoftDelete(6)
+	return s
+}
+
+func TestSearch(t *testing.T) {
+	s := searchStore(t)
+	tests := []struct {
+		name string
+		opts SearchOptions
+		want []int
+	}{
+		{"zero options", SearchOptions{}, []int{1, 2, 3, 4, 5}},
+		{"author case-insensitive", SearchOptions{BookFilter: BookFilter{Author: "MARTIN"}}, []int{2, 4}},
+		{"title substring", SearchOptions{BookFilter: BookFilter{Title: "clean"}}, []int{2, 4}},
+		{"year", SearchOptions{BookFilter: BookFilter{Year: 2015}}, []int{1, 5}},
+		{"genre exact, case-insensitive", SearchOptions{BookFilter: BookFilter{Genre: "GO"}}, []int{1, 5}},
+		{"genre is not a substring", SearchOptions{BookFilter: BookFilter{Genre: "arch"}}, []int{}},
+		{"author and year", SearchOptions{BookFilter: BookFilter{Author: "martin", Year: 2017}}, []int{4}},
+		{"genre and year", SearchOptions{BookFilter: BookFilter{Genre: "programming", Year: 2015}}, []int{1}},
+		{"title and genre", SearchOptions{BookFilter: BookFilter{Title: "clean", Genre: "craftsmanship"}}, []int{2}},
+		{"include deleted", SearchOptions{BookFilter: BookFilter{Author: "martin", IncludeDeleted: true}}, []int{2, 4, 6}},
+		{"no match", SearchOptions{BookFilter: BookFilter{Author: "nobody"}}, []int{}},
+		{"query ranks by match position", SearchOptions{Query: "go"}, []int{5, 1}},
+		{"query matches author", SearchOptions{Query: "kennedy"}, []int{5}},
+		{"query with filter", SearchOptions{Query: "clean", BookFilter: BookFilter{Year: 2008}}, []int{2}},
+		{"query skips deleted", SearchOptions{Query: "agile"}, []int{}},
+		{"sort by year", SearchOptions{Sort: "year"}, []int{3, 2, 1, 5, 4}},
+		{"sort descending", SearchOptions{Sort: "-year"}, []int{4, 1, 5, 2, 3}},
+		{"sort overrides relevance", SearchOptions{Query: "clean", Sort: "-year"}, []int{4, 2}},
+		{"filter, sort and page", SearchOptions{BookFilter: BookFilter{Genre: "programming"}, Sort: "title", Limit: 2}, []int{2, 1}},
+		{"offset", SearchOptions{Offset: 3}, []int{4, 5}},
+		{"offset and limit", SearchOptions{Offset: 1, Limit: 2}, []int{2, 3}},
+		{"limit larger than result", SearchOptions{Limit: 10}, []int{1, 2, 3, 4, 5}},
+		{"offset past the end", SearchOptions{Offset: 5}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.Search(tt.opts)
+			if got == nil {
+				t.Fatal("expected empty slice, got nil")
+			}
+			ids := make([]int, len(got))
+			for i, b := range got {
+				ids[i] = b.ID
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("expected IDs %v, got %v", tt.want, ids)
+			}
+		})
+	}
+}
+
+func TestSearchOptionsValidate(t *testing.T) {
+	tests := []struct {
+		opts SearchOptions
+		ok   bool
+	}{
+		{SearchOptions{}, true},
+		{SearchOptions{Sort: "-title", Offset: 2, Limit: 5}, true},
+		{SearchOptions{Sort: "rating"}, false},
+		{SearchOptions{Sort: "-"}, false},
+		{SearchOptions{Offset: -1}, false},
+		{SearchOptions{Limit: -1}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v): expected ok=%v, got %v", tt.opts, tt.ok, err)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package models
+
+import "sort"
+
+// DecadeCount — десятилетие (1990, 2000, ...) и число книг, изданных в нём
+type DecadeCount struct {
+	Decade int `json:"decade"`
+	Count  int `json:"count"`
+}
+
+// Stats — сводка по каталогу для /api/books/stats
+type Stats struct {
+	Total      int           `json:"total"`
+	Decades    []DecadeCount `json:"decades"`
+	TopAuthors []AuthorCount `json:"top_authors"`
+}
+
+// DecadeBuckets раскладывает книги по десятилетиям года издания.
+// Результат отсортирован по возрастанию десятилетия; пустые десятилетия
+// не попадают в список.
+func DecadeBuckets(books []Book) []DecadeCount {
+	counts := make(map[int]int)
+	for _, b := range books {
+		counts[b.Year/10*10]++
+	}
+
+	list := make([]DecadeCount, 0, len(counts))
+	for decade, n := range counts {
+		list = append(list, DecadeCount{Decade: decade, Count: n})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Decade < list[j].Decade })
+	return list
+}
+
+// TopAuthors возвращает не больше n авторов с наибольшим числом книг;
+// при равенстве — по алфавиту, как в Authors
+func TopAuthors(authors []AuthorCount, n int) []AuthorCount {
+	top := make([]AuthorCount, len(authors))
+	copy(top, authors)
+	sort.SliceStable(top, func(i, j int) bool { return top[i].Count > top[j].Count })
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// Stats собирает сводку по неудалённым книгам: общее число, распределение
+// по десятилетиям и topN самых частых авторов
+func (s *Store) Stats(topN int) Stats {
+	books := s.Find(BookFilter{})
+	return Stats{
+		Total:      len(books),
+		Decades:    DecadeBuckets(books),
+		TopAuthors: TopAuthors(countAuthors(books), topN),
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecadeBuckets(t *testing.T) {
+	var books []Book
+	for _, year := range []int{1999, 1990, 2008, 2015, 2019, 2010, 1985} {
+		books = append(books, Book{Year: year})
+	}
+
+	want := []DecadeCount{
+		{Decade: 1980, Count: 1},
+		{Decade: 1990, Count: 2},
+		{Decade: 2000, Count: 1},
+		{Decade: 2010, Count: 3},
+	}
+	if got := DecadeBuckets(books); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if got := DecadeBuckets(nil); len(got) != 0 {
+		t.Errorf("expected no buckets for empty catalog, got %+v", got)
+	}
+}
+
+func TestTopAuthors(t *testing.T) {
+	authors := []AuthorCount{
+		{Author: "Andrew Hunt", Count: 1},
+		{Author: "Martin Fowler", Count: 2},
+		{Author: "Robert C. Martin", Count: 3},
+		{Author: "Rob Pike", Count: 2},
+	}
+
+	want := []AuthorCount{
+		{Author: "Robert C. Martin", Count: 3},
+		{Author: "Martin Fowler", Count: 2},
+		{Author: "Rob Pike", Count: 2},
+	}
+	if got := TopAuthors(authors, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if authors[0].Author != "Andrew Hunt" {
+		t.Errorf("TopAuthors must not reorder its input, got %+v", authors)
+	}
+}
+
+func TestStoreStats(t *testing.T) {
+	s := NewStore()
+	s.Create(Book{Title: "Clean Architecture", Author: "Robert C. Martin", Year: 2017})
+	s.SoftDelete(3)
+
+	got := s.Stats(1)
+	if got.Total != 3 {
+		t.Errorf("expected total 3, got %d", got.Total)
+	}
+	wantDecades := []DecadeCount{{Decade: 2000, Count: 1}, {Decade: 2010, Count: 2}}
+	if !reflect.DeepEqual(got.Decades, wantDecades) {
+		t.Errorf("expected decades %+v, got %+v", wantDecades, got.Decades)
+	}
+	wantTop := []AuthorCount{{Author: "Robert C. Martin", Count: 2}}
+	if !reflect.DeepEqual(got.TopAuthors, wantTop) {
+		t.Errorf("expected top authors %+v, got %+v", wantTop, got.TopAuthors)
+	}
+}
@@ -101,6 +101,9 @@ func main() {
 		JobTimeout: time.Duration(cfg.JobTimeout) * time.Second,
 	})
 
+	// Повторно ставим в очередь задачи, прерванные предыдущей остановкой.
+	pool.Restore()
+
 	// Слой хендлеров.
 	h := handler.New(jobStore, pool)
 	mux := http.NewServeMux()
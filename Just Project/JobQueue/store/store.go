@@ -100,3 +100,18 @@ func (s *MemoryStore) List() []Job {
 	}
 	return result
 }
+
+// Unfinished возвращает копии задач в статусах «queued» и «running».
+// Хранилище не меняет.
+func (s *MemoryStore) Unfinished() []Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Job
+	for _, j := range s.jobs {
+		if j.Status == StatusQueued || j.Status == StatusRunning {
+			result = append(result, *j)
+		}
+	}
+	return result
+}
@@ -82,3 +82,18 @@ func TestGetReturnsCopy(t *testing.T) {
 		t.Error("Get should return a copy; original was mutated")
 	}
 }
+
+func TestUnfinished(t *testing.T) {
+	s := New()
+	s.Save(&Job{ID: "q", Task: "t", Status: StatusQueued, CreatedAt: time.Now(), UpdatedAt: time.Now()})
+	s.Save(&Job{ID: "r", Task: "t", Status: StatusRunning, CreatedAt: time.Now(), UpdatedAt: time.Now()})
+	s.Save(&Job{ID: "d", Task: "t", Status: StatusCompleted, CreatedAt: time.Now(), UpdatedAt: time.Now()})
+
+	if jobs := s.Unfinished(); len(jobs) != 2 {
+		t.Fatalf("expected 2 unfinished jobs, got %d", len(jobs))
+	}
+	// Unfinished только читает: статус «running» остаётся как есть.
+	if got, _ := s.Get("r"); got.Status != StatusRunning {
+		t.Errorf("expected %q, got %q", StatusRunning, got.Status)
+	}
+}
@@ -65,14 +65,20 @@ type Pool struct {
 	store *store.MemoryStore
 	cfg   Config
 	wg    sync.WaitGroup // ожидание завершения всех воркеров при shutdown
+
+	mu      sync.Mutex          // защищает pending и running
+	pending map[string]struct{} // ID задач, уже находящихся в канале
+	running map[string]struct{} // ID задач, которые сейчас выполняет воркер
 }
 
 // NewPool создаёт пул и запускает воркеры.
 func NewPool(s *store.MemoryStore, cfg Config) *Pool {
 	p := &Pool{
-		jobs:  make(chan string, cfg.QueueSize), // буферизованный канал
-		store: s,
-		cfg:   cfg,
+		jobs:    make(chan string, cfg.QueueSize), // буферизованный канал
+		store:   s,
+		cfg:     cfg,
+		pending: make(map[string]struct{}),
+		running: make(map[string]struct{}),
 	}
 
 	// Запускаем N воркеров. Каждый — отдельная горутина.
@@ -88,9 +94,22 @@ func NewPool(s *store.MemoryStore, cfg Config) *Pool {
 }
 
 // Submit помещает ID задачи в канал. Возвращает false, если очередь переполнена.
+// Повторный Submit задачи, которая ещё ждёт в канале, ничего не делает.
 func (p *Pool) Submit(jobID string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.pending[jobID]; ok {
+		return true // уже в очереди — не дублируем
+	}
+	return p.push(jobID)
+}
+
+// push кладёт ID в канал без ожидания. Вызывается под блокировкой p.mu.
+func (p *Pool) push(jobID string) bool {
 	select {
 	case p.jobs <- jobID:
+		p.pending[jobID] = struct{}{}
 		return true
 	default:
 		// Буфер полон — задача отклоняется.
@@ -98,6 +117,39 @@ func (p *Pool) Submit(jobID string) bool {
 	}
 }
 
+// Restore повторно ставит в очередь задачи, оставшиеся незавершёнными
+// после предыдущего запуска (статусы «queued» и «running»); прерванные
+// задачи снова получают статус «queued». Вызывается при старте, после
+// загрузки хранилища. Задачи, которые уже ждут в канале или выполняются
+// воркером, пропускаются, поэтому повторный вызов ничего не дублирует.
+// Возвращает число задач, поставленных в очередь этим вызовом.
+func (p *Pool) Restore() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := 0
+	for _, job := range p.store.Unfinished() {
+		if _, ok := p.pending[job.ID]; ok {
+			continue
+		}
+		if _, ok := p.running[job.ID]; ok {
+			continue
+		}
+		if job.Status == store.StatusRunning {
+			_ = p.store.UpdateStatus(job.ID, store.StatusQueued, "")
+		}
+		if !p.push(job.ID) {
+			log.Printf("[pool] queue full, job %s not restored", job.ID)
+			continue
+		}
+		n++
+	}
+	if n > 0 {
+		log.Printf("[pool] restored %d unfinished jobs", n)
+	}
+	return n
+}
+
 // Stop закрывает канал задач и ожидает завершения всех воркеров (graceful shutdown).
 func (p *Pool) Stop() {
 	log.Println("[pool] shutting down…")
@@ -116,7 +168,16 @@ func (p *Pool) runWorker(id int) {
 	// range по каналу: цикл продолжается, пока канал открыт.
 	// После close(p.jobs) цикл дочитает оставшиеся элементы и завершится.
 	for jobID := range p.jobs {
+		p.mu.Lock()
+		delete(p.pending, jobID) // задача покинула канал
+		p.running[jobID] = struct{}{}
+		p.mu.Unlock()
+
 		p.processJob(id, jobID)
+
+		p.mu.Lock()
+		delete(p.running, jobID)
+		p.mu.Unlock()
 	}
 
 	log.Printf("[worker %d] stopped", id)
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -21,6 +22,26 @@ func withFastExecutor(t *testing.T) {
 	t.Cleanup(func() { executeTask = original })
 }
 
+// waitForStatus опрашивает хранилище, пока задача id не получит статус want,
+// и проваливает тест, если этого не случилось за пару секунд.
+func waitForStatus(t *testing.T, s *store.MemoryStore, id string, want store.Status) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		job, err := s.Get(id)
+		if err == nil && job.Status == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				t.Fatalf("job %s: %v", id, err)
+			}
+			t.Fatalf("job %s: expected %q, got %q", id, want, job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
 // ---------- Тесты ----------
 
 func TestPoolProcessesJob(t *testing.T) {
@@ -83,9 +104,10 @@ func TestPoolQueueFull(t *testing.T) {
 	s := store.New()
 	// Буфер = 1, воркер = 0 (не запускаем воркеров, чтобы канал оставался полным).
 	p := &Pool{
-		jobs:  make(chan string, 1),
-		store: s,
-		cfg:   Config{},
+		jobs:    make(chan string, 1),
+		store:   s,
+		cfg:     Config{},
+		pending: make(map[string]struct{}),
 	}
 
 	// Первый submit занимает единственный слот.
@@ -129,3 +151,96 @@ func TestPoolJobTimeout(t *testing.T) {
 		t.Errorf("expected %q, got %q", store.StatusCancelled, job.Status)
 	}
 }
+
+func TestPoolRestoresUnfinishedJobs(t *testing.T) {
+	withFastExecutor(t)
+
+	// Хранилище переживает «перезапуск»: старый пул остановлен, не успев
+	// обработать задачи.
+	s := store.New()
+	old := NewPool(s, Config{NumWorkers: 0, QueueSize: 10, JobTimeout: 5 * time.Second})
+	old.Stop()
+
+	s.Save(&store.Job{
+		ID: "queued", Task: "t", Status: store.StatusQueued,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	})
+	s.Save(&store.Job{
+		ID: "running", Task: "t", Status: store.StatusRunning,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	})
+	s.Save(&store.Job{
+		ID: "done", Task: "t", Status: store.StatusCompleted,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	})
+
+	// Новый пул без воркеров: проверяем постановку в очередь без гонки с обработкой.
+	p := NewPool(s, Config{NumWorkers: 0, QueueSize: 10, JobTimeout: 5 * time.Second})
+	if n := p.Restore(); n != 2 {
+		t.Fatalf("expected 2 restored jobs, got %d", n)
+	}
+	// Повторный Restore не должен дублировать задачи в канале.
+	if n := p.Restore(); n != 0 {
+		t.Errorf("expected second Restore to restore nothing, got %d", n)
+	}
+	if len(p.jobs) != 2 {
+		t.Fatalf("expected 2 jobs in queue, got %d", len(p.jobs))
+	}
+
+	job, _ := s.Get("running")
+	if job.Status != store.StatusQueued {
+		t.Errorf("expected running job reset to %q, got %q", store.StatusQueued, job.Status)
+	}
+
+	// Запускаем воркер и убеждаемся, что задачи обработаны.
+	p.wg.Add(1)
+	go p.runWorker(1)
+	defer p.Stop()
+
+	for _, id := range []string{"queued", "running"} {
+		waitForStatus(t, s, id, store.StatusCompleted)
+	}
+}
+
+func TestPoolRestoreSkipsRunningJob(t *testing.T) {
+	// Executor блокируется, пока тест его не отпустит, и считает запуски.
+	release := make(chan struct{})
+	var mu sync.Mutex
+	runs := 0
+	original := executeTask
+	executeTask = func(_ context.Context, _ string) error {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		<-release
+		return nil
+	}
+	t.Cleanup(func() { executeTask = original })
+
+	s := store.New()
+	p := NewPool(s, Config{NumWorkers: 2, QueueSize: 10, JobTimeout: 5 * time.Second})
+	defer p.Stop()
+
+	s.Save(&store.Job{
+		ID: "busy", Task: "t", Status: store.StatusQueued,
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	})
+	p.Submit("busy")
+	waitForStatus(t, s, "busy", store.StatusRunning)
+
+	// Задача уже у воркера — Restore не должен ставить её повторно.
+	if n := p.Restore(); n != 0 {
+		t.Errorf("expected no restored jobs while busy is running, got %d", n)
+	}
+	if job, _ := s.Get("busy"); job.Status != store.StatusRunning {
+		t.Errorf("expected busy to stay %q, got %q", store.StatusRunning, job.Status)
+	}
+
+	close(release)
+	waitForStatus(t, s, "busy", store.StatusCompleted)
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 1 {
+		t.Errorf("expected busy to run once, ran %d times", runs)
+	}
+}
@@ -0,0 +1,28 @@
+package generator
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed common.txt
+var commonList string
+
+// commonPasswords is the set of well-known weak passwords, lowercased.
+var commonPasswords = parseCommon(commonList)
+
+// parseCommon turns a newline-separated list into a lowercase lookup set.
+func parseCommon(list string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Fields(list) {
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	return set
+}
+
+// isCommon reports whether password appears in the common-passwords list,
+// ignoring case.
+func isCommon(password string) bool {
+	_, ok := commonPasswords[strings.ToLower(password)]
+	return ok
+}
@@ -0,0 +1,43 @@
+package generator
+
+import "testing"
+
+func TestIsCommon(t *testing.T) {
+	for _, pw := range []string{"password", "QWERTY", "Admin123"} {
+		if !isCommon(pw) {
+			t.Errorf("expected %q to be common", pw)
+		}
+	}
+	if isCommon("G3$kLp!9qWzR@mN5") {
+		t.Error("random password should not be common")
+	}
+}
+
+func TestGenerateRejectCommon(t *testing.T) {
+	// Seed the list so that every 2-letter combination but "bb" is flagged.
+	original := commonPasswords
+	commonPasswords = parseCommon("aa AB ba")
+	t.Cleanup(func() { commonPasswords = original })
+
+	opts := Options{Length: 2, CustomCharset: "ab", RejectCommon: true}
+	for i := 0; i < 50; i++ {
+		pw, err := Generate(opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if pw != "bb" {
+			t.Fatalf("expected the only non-common value %q, got %q", "bb", pw)
+		}
+	}
+}
+
+func TestGenerateRejectCommonExhausted(t *testing.T) {
+	original := commonPasswords
+	commonPasswords = parseCommon("a")
+	t.Cleanup(func() { commonPasswords = original })
+
+	opts := Options{Length: 1, CustomCharset: "A", RejectCommon: true}
+	if _, err := Generate(opts); err == nil {
+		t.Fatal("expected error when every possible password is common")
+	}
+}
@@ -4,6 +4,9 @@ package generator
 import (
 	"crypto/rand"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"math/big"
 	"strings"
 )
@@ -13,51 +16,389 @@ const (
 	uppercase = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	digits    = "0123456789"
 	symbols   = "!@#$%^&*()-_=+[]{}|;:',.<>?/`~"
+
+	// ambiguous lists characters that are easy to misread (l/I/1, O/0).
+	ambiguous = "lI1O0"
+
+	// maxAttempts bounds redraws and reshuffles so impossible constraints
+	// end in an error instead of an endless loop.
+	maxAttempts = 1000
 )
 
 // Options holds the configuration for password generation.
 type Options struct {
-	Length     int
-	UseDigits  bool
-	UseSymbols bool
+	Length           int
+	UseDigits        bool
+	UseSymbols       bool
+	ExcludeAmbiguous bool // drop easily confused characters (l, I, 1, O, 0)
+	RequireEachClass bool // at least one character from every enabled class
+	DigitsOnly       bool // PIN mode: only 0-9, letters and symbols are ignored
+	MinDigits        int  // minimum number of digits; requires digits in the pool
+	NoRepeats        bool // never place the same character twice in a row
+	NoSequences      bool // no runs like "abc", "321" (3 consecutive code points)
+	RejectCommon     bool // never return an entry from the common-passwords list
+
+	// CustomCharset, when non-empty, replaces the built-in sets entirely;
+	// UseDigits, UseSymbols and ExcludeAmbiguous are ignored.
+	CustomCharset string
+
+	// ExcludeChars lists characters removed from the final pool
+	// (e.g. "$`'\"" for shell-safe passwords).
+	ExcludeChars string
+
+	// random is the randomness source; set by GenerateWith.
+	random io.Reader
 }
 
 // Generate creates a cryptographically secure random password based on the
-// provided options. It returns an error if the requested length is less than 1
-// or if no character sets are available (which cannot happen with the current
-// design because letters are always included).
+// provided options. Length is measured in characters (runes), so custom
+// non-ASCII sets are supported. It returns an error if the requested length is
+// less than 1, if the character pool ends up empty, if the required
+// characters (RequireEachClass, MinDigits) cannot fit into Length, or if the
+// adjacency rules (NoRepeats, NoSequences) cannot be satisfied with the charset.
+// With RejectCommon, results found in the common-passwords list are redrawn.
 func Generate(opts Options) (string, error) {
+	return GenerateWith(rand.Reader, opts)
+}
+
+// GenerateWith is like Generate but reads randomness from r instead of
+// crypto/rand. Feeding a fixed reader makes the output reproducible,
+// which is what tests need; production code should use Generate.
+func GenerateWith(r io.Reader, opts Options) (string, error) {
+	opts.random = r
+
 	if opts.Length < 1 {
 		return "", errors.New("password length must be at least 1")
 	}
 
-	// Build the character pool — letters are always included.
-	charset := lowercase + uppercase
-	if opts.UseDigits {
-		charset += digits
+	charset, err := buildCharset(opts)
+	if err != nil {
+		return "", err
 	}
-	if opts.UseSymbols {
-		charset += symbols
+	if opts.NoRepeats && opts.Length > 1 && len(charset) < 2 {
+		return "", errors.New("no-repeats requires at least 2 distinct characters")
 	}
 
-	// Pre-allocate a builder with exact capacity.
-	var sb strings.Builder
-	sb.Grow(opts.Length)
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		// Collect the mandatory characters, then scatter them among random filler.
+		required, err := requiredChars(opts, charset)
+		if err != nil {
+			return "", err
+		}
 
-	for i := 0; i < opts.Length; i++ {
-		idx, err := cryptoRandInt(len(charset))
+		chars, err := arrange(opts, charset, required)
 		if err != nil {
 			return "", err
 		}
-		sb.WriteByte(charset[idx])
+
+		pw := string(chars)
+		if opts.RejectCommon && isCommon(pw) {
+			continue
+		}
+		return pw, nil
+	}
+	return "", errors.New("could not generate a password outside the common-passwords list")
+}
+
+// GenerateBatch returns n distinct passwords generated with opts. A collision
+// simply triggers another draw; if maxAttempts draws in a row collide, the
+// option space is assumed too small and an error is returned.
+func GenerateBatch(n int, opts Options) ([]string, error) {
+	seen := make(map[string]struct{}, n)
+	result := make([]string, 0, n)
+
+	for collisions := 0; len(result) < n; {
+		pw, err := Generate(opts)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := seen[pw]; dup {
+			collisions++
+			if collisions >= maxAttempts {
+				return nil, fmt.Errorf("cannot generate %d unique passwords with the given length and charset", n)
+			}
+			continue
+		}
+
+		collisions = 0
+		seen[pw] = struct{}{}
+		result = append(result, pw)
+	}
+	return result, nil
+}
+
+// arrange places the required characters at random positions and fills the
+// remaining slots from charset, honoring the adjacency rules in opts.
+// Layouts that hit a dead end are retried up to maxAttempts times.
+func arrange(opts Options, charset, required []rune) ([]rune, error) {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		chars, ok, err := tryArrange(opts, charset, required)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return chars, nil
+		}
+	}
+	return nil, errors.New("could not satisfy character constraints with the given charset")
+}
+
+// tryArrange makes a single layout attempt. ok is false when the random
+// layout cannot be completed under the adjacency rules.
+func tryArrange(opts Options, charset, required []rune) (chars []rune, ok bool, err error) {
+	chars = make([]rune, opts.Length)
+	fixed := make([]bool, opts.Length)
+
+	positions, err := randomPositions(opts.random, opts.Length, len(required))
+	if err != nil {
+		return nil, false, err
+	}
+	for k, pos := range positions {
+		chars[pos] = required[k]
+		fixed[pos] = true
+	}
+
+	// Fill left to right so every check sees a complete prefix.
+	for i := range chars {
+		if fixed[i] {
+			if !allowedAfter(opts, chars[:i], chars[i]) {
+				return nil, false, nil
+			}
+			continue
+		}
+
+		c, found, err := pickChar(opts, charset, chars, fixed, i)
+		if err != nil || !found {
+			return nil, false, err
+		}
+		chars[i] = c
+	}
+	return chars, true, nil
+}
+
+// pickChar draws a character for slot i that may follow chars[:i] and does
+// not break the required characters placed right after it.
+func pickChar(opts Options, charset, chars []rune, fixed []bool, i int) (rune, bool, error) {
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		c, err := randomChar(opts.random, charset)
+		if err != nil {
+			return 0, false, err
+		}
+		if !allowedAfter(opts, chars[:i], c) {
+			continue
+		}
+
+		chars[i] = c
+		if fitsFixedNeighbors(opts, chars, fixed, i) {
+			return c, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// fitsFixedNeighbors checks the required characters that directly follow
+// slot i, whose left context is already complete.
+func fitsFixedNeighbors(opts Options, chars []rune, fixed []bool, i int) bool {
+	for j := i + 1; j < len(chars) && fixed[j]; j++ {
+		if !allowedAfter(opts, chars[:j], chars[j]) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowedAfter reports whether c may be appended to prev.
+func allowedAfter(opts Options, prev []rune, c rune) bool {
+	n := len(prev)
+	if opts.NoRepeats && n > 0 && prev[n-1] == c {
+		return false
+	}
+	if opts.NoSequences && n > 1 {
+		a, b := prev[n-2], prev[n-1]
+		if (b == a+1 && c == b+1) || (b == a-1 && c == b-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// requiredChars returns the characters every password must contain:
+// one per class for RequireEachClass, topped up with digits until
+// MinDigits is satisfied.
+func requiredChars(opts Options, charset []rune) ([]rune, error) {
+	chars := make([]rune, 0, opts.Length)
+
+	if opts.RequireEachClass {
+		classes := charClasses(opts)
+		if opts.Length < len(classes) {
+			return nil, fmt.Errorf("password length %d is too short for %d required character classes",
+				opts.Length, len(classes))
+		}
+		for _, class := range classes {
+			c, err := randomChar(opts.random, []rune(class))
+			if err != nil {
+				return nil, err
+			}
+			chars = append(chars, c)
+		}
+	}
+
+	if opts.MinDigits > 0 {
+		if opts.MinDigits > opts.Length {
+			return nil, fmt.Errorf("minimum digits %d exceeds password length %d", opts.MinDigits, opts.Length)
+		}
+		pool := digitsIn(charset)
+		if len(pool) == 0 {
+			return nil, errors.New("minimum digits requires digits to be enabled")
+		}
+
+		have := len(digitsIn(chars))
+		if len(chars)-have+opts.MinDigits > opts.Length {
+			return nil, fmt.Errorf("password length %d is too short for the required characters", opts.Length)
+		}
+		for ; have < opts.MinDigits; have++ {
+			c, err := randomChar(opts.random, pool)
+			if err != nil {
+				return nil, err
+			}
+			chars = append(chars, c)
+		}
+	}
+
+	return chars, nil
+}
+
+// digitsIn returns the ASCII digits contained in chars.
+func digitsIn(chars []rune) []rune {
+	var result []rune
+	for _, r := range chars {
+		if r >= '0' && r <= '9' {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// Entropy returns the theoretical strength in bits of a password generated
+// with opts: Length * log2(charset size). It returns 0 for invalid options.
+func Entropy(opts Options) float64 {
+	if opts.Length < 1 {
+		return 0
+	}
+	charset, err := buildCharset(opts)
+	if err != nil {
+		return 0
+	}
+	return float64(opts.Length) * math.Log2(float64(len(charset)))
+}
+
+// charClasses returns the enabled character classes, with ambiguous
+// characters already removed if requested. Empty classes are skipped.
+// A custom charset forms a single class of its own.
+func charClasses(opts Options) []string {
+	if opts.CustomCharset != "" {
+		custom := removeChars(dedupRunes(opts.CustomCharset), opts.ExcludeChars)
+		if custom == "" {
+			return nil
+		}
+		return []string{custom}
+	}
+
+	var classes []string
+	if opts.DigitsOnly {
+		classes = []string{digits}
+	} else {
+		// Letters are always included.
+		classes = []string{lowercase, uppercase}
+		if opts.UseDigits {
+			classes = append(classes, digits)
+		}
+		if opts.UseSymbols {
+			classes = append(classes, symbols)
+		}
+	}
+
+	result := classes[:0]
+	for _, class := range classes {
+		if opts.ExcludeAmbiguous {
+			class = removeChars(class, ambiguous)
+		}
+		class = removeChars(class, opts.ExcludeChars)
+		if class != "" {
+			result = append(result, class)
+		}
 	}
+	return result
+}
 
-	return sb.String(), nil
+// buildCharset assembles the character pool described by opts.
+// It returns an error if the resulting pool is empty.
+func buildCharset(opts Options) ([]rune, error) {
+	charset := []rune(strings.Join(charClasses(opts), ""))
+	if len(charset) == 0 {
+		if opts.ExcludeChars != "" {
+			return nil, fmt.Errorf("character set is empty after excluding %q", opts.ExcludeChars)
+		}
+		return nil, errors.New("character set is empty")
+	}
+	return charset, nil
+}
+
+// dedupRunes returns s with repeated runes removed, keeping first occurrences.
+func dedupRunes(s string) string {
+	seen := make(map[rune]bool, len(s))
+	var sb strings.Builder
+	for _, r := range s {
+		if !seen[r] {
+			seen[r] = true
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// removeChars returns s with every character from exclude removed.
+func removeChars(s, exclude string) string {
+	if exclude == "" {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(exclude, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// randomChar picks a uniformly random rune from charset.
+func randomChar(r io.Reader, charset []rune) (rune, error) {
+	idx, err := randInt(r, len(charset))
+	if err != nil {
+		return 0, err
+	}
+	return charset[idx], nil
+}
+
+// randomPositions returns k distinct indices in [0, n) in random order,
+// using a partial Fisher–Yates shuffle.
+func randomPositions(r io.Reader, n, k int) ([]int, error) {
+	idx := make([]int, n)
+	for i := range idx {
+		idx[i] = i
+	}
+	for i := 0; i < k; i++ {
+		j, err := randInt(r, n-i)
+		if err != nil {
+			return nil, err
+		}
+		idx[i], idx[i+j] = idx[i+j], idx[i]
+	}
+	return idx[:k], nil
 }
 
-// cryptoRandInt returns a uniform random int in [0, max) using crypto/rand.
-func cryptoRandInt(max int) (int, error) {
-	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+// randInt returns a uniform random int in [0, max) read from r.
+func randInt(r io.Reader, max int) (int, error) {
+	n, err := rand.Int(r, big.NewInt(int64(max)))
 	if err != nil {
 		return 0, err
 	}
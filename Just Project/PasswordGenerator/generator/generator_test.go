@@ -1,6 +1,8 @@
 package generator
 
 import (
+	"bytes"
+	"math"
 	"strings"
 	"testing"
 	"unicode"
@@ -155,3 +157,283 @@ func TestGenerateUniqueness(t *testing.T) {
 		t.Errorf("two generated passwords are identical: %q", a)
 	}
 }
+
+func TestGenerateExcludeAmbiguous(t *testing.T) {
+	opts := Options{Length: 200, UseDigits: true, UseSymbols: true, ExcludeAmbiguous: true}
+
+	password, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(password) != opts.Length {
+		t.Errorf("expected length %d, got %d", opts.Length, len(password))
+	}
+	if strings.ContainsAny(password, ambiguous) {
+		t.Errorf("password %q contains ambiguous characters", password)
+	}
+}
+
+func TestGenerateRequireEachClass(t *testing.T) {
+	opts := Options{Length: 4, UseDigits: true, UseSymbols: true, RequireEachClass: true}
+
+	// Short passwords make a missing class very likely without the guarantee.
+	for i := 0; i < 200; i++ {
+		password, err := Generate(opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(password) != opts.Length {
+			t.Fatalf("expected length %d, got %d", opts.Length, len(password))
+		}
+		assertContainsAny(t, password, lowercase, "lowercase letter")
+		assertContainsAny(t, password, uppercase, "uppercase letter")
+		assertContainsAny(t, password, digits, "digit")
+		assertContainsAny(t, password, symbols, "symbol")
+	}
+}
+
+func TestGenerateRequireEachClassTooShort(t *testing.T) {
+	opts := Options{Length: 3, UseDigits: true, UseSymbols: true, RequireEachClass: true}
+
+	if _, err := Generate(opts); err == nil {
+		t.Fatal("expected error when length is smaller than the number of classes")
+	}
+}
+
+func TestGenerateCustomCharset(t *testing.T) {
+	// Duplicates in the custom set must not skew the distribution or fail.
+	opts := Options{Length: 100, UseSymbols: true, CustomCharset: "abcabc123"}
+
+	password, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(password) != opts.Length {
+		t.Errorf("expected length %d, got %d", opts.Length, len(password))
+	}
+	for _, r := range password {
+		if !strings.ContainsRune("abc123", r) {
+			t.Fatalf("password %q contains %q outside the custom charset", password, r)
+		}
+	}
+}
+
+func TestGenerateCustomCharsetUnicode(t *testing.T) {
+	password, err := Generate(Options{Length: 10, CustomCharset: "αβγ"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := len([]rune(password)); n != 10 {
+		t.Errorf("expected 10 runes, got %d", n)
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want float64
+	}{
+		// 16 * log2(16) = 64 bits exactly.
+		{"custom_16", Options{Length: 16, CustomCharset: "0123456789abcdef"}, 64},
+		// 12 * log2(52) ≈ 68.41 bits.
+		{"letters", Options{Length: 12}, 12 * math.Log2(52)},
+		// 20 * log2(62) ≈ 119.08 bits.
+		{"letters_digits", Options{Length: 20, UseDigits: true}, 119.08},
+		{"invalid_length", Options{Length: 0}, 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Entropy(tc.opts)
+			if math.Abs(got-tc.want) > 0.01 {
+				t.Errorf("expected %.2f bits, got %.2f", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestGenerateExcludeChars(t *testing.T) {
+	forbidden := "$`'\"\\"
+	opts := Options{Length: 300, UseDigits: true, UseSymbols: true, ExcludeChars: forbidden}
+
+	password, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.ContainsAny(password, forbidden) {
+		t.Errorf("password %q contains excluded characters", password)
+	}
+}
+
+func TestGenerateExcludeCharsEmptiesCharset(t *testing.T) {
+	opts := Options{Length: 8, CustomCharset: "abc", ExcludeChars: "cba"}
+
+	if _, err := Generate(opts); err == nil {
+		t.Fatal("expected error when exclusions empty the charset")
+	}
+}
+
+func TestGenerateDigitsOnly(t *testing.T) {
+	opts := Options{Length: 6, UseSymbols: true, DigitsOnly: true}
+
+	pin, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pin) != opts.Length {
+		t.Errorf("expected length %d, got %d", opts.Length, len(pin))
+	}
+	for _, r := range pin {
+		if !unicode.IsDigit(r) {
+			t.Fatalf("PIN %q contains non-digit %q", pin, r)
+		}
+	}
+
+	if _, err := Generate(Options{Length: 0, DigitsOnly: true}); err == nil {
+		t.Error("expected error for zero-length PIN")
+	}
+}
+
+func TestGenerateMinDigits(t *testing.T) {
+	opts := Options{Length: 8, UseDigits: true, MinDigits: 3}
+
+	for i := 0; i < 100; i++ {
+		password, err := Generate(opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(password) != opts.Length {
+			t.Fatalf("expected length %d, got %d", opts.Length, len(password))
+		}
+
+		count := 0
+		for _, r := range password {
+			if unicode.IsDigit(r) {
+				count++
+			}
+		}
+		if count < opts.MinDigits {
+			t.Fatalf("password %q has %d digits, want at least %d", password, count, opts.MinDigits)
+		}
+	}
+}
+
+func TestGenerateMinDigitsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"exceeds_length", Options{Length: 4, UseDigits: true, MinDigits: 5}},
+		{"digits_disabled", Options{Length: 8, MinDigits: 1}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Generate(tc.opts); err == nil {
+				t.Fatal("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestGenerateNoRepeats(t *testing.T) {
+	// A tiny charset makes accidental repeats almost certain without the flag.
+	opts := Options{Length: 100, CustomCharset: "ab1", MinDigits: 10, NoRepeats: true}
+
+	password, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 1; i < len(password); i++ {
+		if password[i] == password[i-1] {
+			t.Fatalf("password %q repeats %q at position %d", password, password[i], i)
+		}
+	}
+}
+
+func TestGenerateNoRepeatsSingleChar(t *testing.T) {
+	opts := Options{Length: 5, CustomCharset: "a", NoRepeats: true}
+
+	if _, err := Generate(opts); err == nil {
+		t.Fatal("expected error for a single-character charset")
+	}
+}
+
+// zeroReader yields an endless stream of zero bytes, which makes every
+// random index 0.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+func TestGenerateWithDeterministicReader(t *testing.T) {
+	got, err := GenerateWith(zeroReader{}, Options{Length: 5, UseDigits: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "aaaaa" {
+		t.Errorf("expected %q, got %q", "aaaaa", got)
+	}
+
+	// The same byte stream must always produce the same password.
+	seed := bytes.Repeat([]byte{7, 42, 199, 3, 250, 18, 91, 64}, 64)
+	opts := Options{Length: 12, UseDigits: true, UseSymbols: true, RequireEachClass: true}
+
+	a, err := GenerateWith(bytes.NewReader(seed), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := GenerateWith(bytes.NewReader(seed), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a != b {
+		t.Errorf("expected reproducible output, got %q and %q", a, b)
+	}
+}
+
+func TestGenerateNoSequences(t *testing.T) {
+	// With only four consecutive characters, runs like "abc" or "dcb" would
+	// appear constantly without the flag.
+	opts := Options{Length: 200, CustomCharset: "abcd", RequireEachClass: true, NoSequences: true}
+
+	password, err := Generate(opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := []rune(password)
+	for i := 2; i < len(r); i++ {
+		up := r[i-1] == r[i-2]+1 && r[i] == r[i-1]+1
+		down := r[i-1] == r[i-2]-1 && r[i] == r[i-1]-1
+		if up || down {
+			t.Fatalf("password %q has sequence %q at position %d", password, string(r[i-2:i+1]), i-2)
+		}
+	}
+}
+
+func TestGenerateBatchUnique(t *testing.T) {
+	// "ab" with length 2 allows exactly 4 distinct passwords.
+	opts := Options{Length: 2, CustomCharset: "ab"}
+
+	passwords, err := GenerateBatch(4, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	seen := make(map[string]bool)
+	for _, pw := range passwords {
+		if seen[pw] {
+			t.Fatalf("duplicate password %q in %v", pw, passwords)
+		}
+		seen[pw] = true
+	}
+	if len(seen) != 4 {
+		t.Errorf("expected 4 unique passwords, got %d", len(seen))
+	}
+
+	if _, err := GenerateBatch(5, opts); err == nil {
+		t.Error("expected error when more passwords are requested than can exist")
+	}
+}
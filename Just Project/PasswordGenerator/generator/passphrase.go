@@ -0,0 +1,39 @@
+package generator
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"errors"
+	"strings"
+)
+
+//go:embed wordlist.txt
+var defaultWordlist string
+
+// DefaultWordlist returns the small built-in list of words used for
+// passphrases when the caller doesn't supply its own.
+func DefaultWordlist() []string {
+	return strings.Fields(defaultWordlist)
+}
+
+// GeneratePassphrase picks wordCount words uniformly at random from wordlist
+// (using crypto/rand) and joins them with separator.
+func GeneratePassphrase(wordCount int, separator string, wordlist []string) (string, error) {
+	if wordCount < 1 {
+		return "", errors.New("word count must be at least 1")
+	}
+	if len(wordlist) == 0 {
+		return "", errors.New("wordlist is empty")
+	}
+
+	words := make([]string, wordCount)
+	for i := range words {
+		idx, err := randInt(rand.Reader, len(wordlist))
+		if err != nil {
+			return "", err
+		}
+		words[i] = wordlist[idx]
+	}
+
+	return strings.Join(words, separator), nil
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratePassphrase(t *testing.T) {
+	wordlist := []string{"alpha", "bravo", "charlie", "delta"}
+
+	phrase, err := GeneratePassphrase(5, "-", wordlist)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	words := strings.Split(phrase, "-")
+	if len(words) != 5 {
+		t.Fatalf("expected 5 words, got %d in %q", len(words), phrase)
+	}
+	for _, w := range words {
+		found := false
+		for _, candidate := range wordlist {
+			if w == candidate {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("word %q is not from the wordlist", w)
+		}
+	}
+}
+
+func TestGeneratePassphraseErrors(t *testing.T) {
+	if _, err := GeneratePassphrase(0, "-", []string{"a"}); err == nil {
+		t.Error("expected error for zero word count")
+	}
+	if _, err := GeneratePassphrase(3, "-", nil); err == nil {
+		t.Error("expected error for empty wordlist")
+	}
+}
+
+func TestDefaultWordlist(t *testing.T) {
+	if n := len(DefaultWordlist()); n < 100 {
+		t.Errorf("expected a non-trivial default wordlist, got %d words", n)
+	}
+}
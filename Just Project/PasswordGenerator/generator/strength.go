@@ -0,0 +1,86 @@
+package generator
+
+import "unicode"
+
+// Strength labels returned by Strength, from weakest to strongest.
+const (
+	StrengthWeak      = "weak"
+	StrengthFair      = "fair"
+	StrengthStrong    = "strong"
+	StrengthExcellent = "excellent"
+)
+
+// Strength gives a human-readable rating of password based on its length and
+// the number of character classes (lowercase, uppercase, digit, symbol) it
+// uses. Anything shorter than 8 characters is always weak.
+func Strength(password string) string {
+	length := len([]rune(password))
+	if length < 8 {
+		return StrengthWeak
+	}
+
+	// Longer passwords and more diverse classes each add a point.
+	score := classCount(password) - 1
+	switch {
+	case length >= 16:
+		score += 3
+	case length >= 12:
+		score += 2
+	default:
+		score++
+	}
+
+	switch {
+	case score >= 6:
+		return StrengthExcellent
+	case score >= 4:
+		return StrengthStrong
+	case score >= 2:
+		return StrengthFair
+	default:
+		return StrengthWeak
+	}
+}
+
+// Character class names used as keys by Classify.
+const (
+	ClassLowercase = "lowercase"
+	ClassUppercase = "uppercase"
+	ClassDigits    = "digits"
+	ClassSymbols   = "symbols"
+)
+
+// Classify counts how many characters of password fall into each class.
+// All four keys are always present, even when their count is zero.
+func Classify(password string) map[string]int {
+	counts := map[string]int{
+		ClassLowercase: 0,
+		ClassUppercase: 0,
+		ClassDigits:    0,
+		ClassSymbols:   0,
+	}
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			counts[ClassLowercase]++
+		case unicode.IsUpper(r):
+			counts[ClassUppercase]++
+		case unicode.IsDigit(r):
+			counts[ClassDigits]++
+		default:
+			counts[ClassSymbols]++
+		}
+	}
+	return counts
+}
+
+// classCount returns how many distinct character classes appear in s.
+func classCount(s string) int {
+	n := 0
+	for _, count := range Classify(s) {
+		if count > 0 {
+			n++
+		}
+	}
+	return n
+}
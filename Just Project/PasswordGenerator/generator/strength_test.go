@@ -0,0 +1,45 @@
+package generator
+
+import "testing"
+
+func TestStrength(t *testing.T) {
+	tests := []struct {
+		password string
+		want     string
+	}{
+		{"", StrengthWeak},
+		{"Ab1!", StrengthWeak},                      // too short regardless of classes
+		{"password", StrengthWeak},                  // 8 chars, one class
+		{"Password1", StrengthFair},                 // 9 chars, three classes
+		{"correcthorsebatterystaple", StrengthFair}, // long but one class
+		{"Tr0ub4dor&3x", StrengthStrong},            // 12 chars, four classes
+		{"abcdEFGH1234wxyz", StrengthStrong},        // 16 chars, three classes
+		{"G3$kLp!9qWzR@mN5xYjT", StrengthExcellent},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.password, func(t *testing.T) {
+			if got := Strength(tc.password); got != tc.want {
+				t.Errorf("Strength(%q) = %q, want %q", tc.password, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	got := Classify("aB3$xyZ_9")
+	want := map[string]int{
+		ClassLowercase: 3,
+		ClassUppercase: 2,
+		ClassDigits:    2,
+		ClassSymbols:   2,
+	}
+	for class, n := range want {
+		if got[class] != n {
+			t.Errorf("%s: expected %d, got %d", class, n, got[class])
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("expected %d classes, got %d: %v", len(want), len(got), got)
+	}
+}
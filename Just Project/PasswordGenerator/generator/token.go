@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+)
+
+// GenerateHexToken reads nBytes from crypto/rand and returns them
+// hex-encoded (2*nBytes characters).
+func GenerateHexToken(nBytes int) (string, error) {
+	b, err := randomBytes(nBytes)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GenerateBase64Token reads nBytes from crypto/rand and returns them encoded
+// with unpadded URL-safe base64, so the token can go into URLs and headers.
+func GenerateBase64Token(nBytes int) (string, error) {
+	b, err := randomBytes(nBytes)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// randomBytes returns n bytes from crypto/rand.
+func randomBytes(n int) ([]byte, error) {
+	if n < 1 {
+		return nil, errors.New("token size must be at least 1 byte")
+	}
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
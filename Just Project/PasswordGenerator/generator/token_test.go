@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestGenerateHexToken(t *testing.T) {
+	for _, n := range []int{1, 16, 32} {
+		token, err := GenerateHexToken(n)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(token) != 2*n {
+			t.Errorf("nBytes=%d: expected length %d, got %d", n, 2*n, len(token))
+		}
+		if _, err := hex.DecodeString(token); err != nil {
+			t.Errorf("token %q is not valid hex: %v", token, err)
+		}
+	}
+}
+
+func TestGenerateBase64Token(t *testing.T) {
+	for _, n := range []int{1, 16, 33} {
+		token, err := GenerateBase64Token(n)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		raw, err := base64.RawURLEncoding.DecodeString(token)
+		if err != nil {
+			t.Fatalf("token %q is not valid base64: %v", token, err)
+		}
+		if len(raw) != n {
+			t.Errorf("expected %d decoded bytes, got %d", n, len(raw))
+		}
+	}
+}
+
+func TestGenerateTokenInvalidSize(t *testing.T) {
+	if _, err := GenerateHexToken(0); err == nil {
+		t.Error("expected error for zero-byte hex token")
+	}
+	if _, err := GenerateBase64Token(-1); err == nil {
+		t.Error("expected error for negative-size base64 token")
+	}
+}
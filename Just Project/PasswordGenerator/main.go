@@ -2,9 +2,11 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -14,10 +16,31 @@ import (
 
 // Config holds the parsed CLI flags.
 type Config struct {
-	Length     int
-	UseDigits  bool
-	UseSymbols bool
-	Count      int
+	Length           int
+	UseDigits        bool
+	UseSymbols       bool
+	ExcludeAmbiguous bool
+	RequireEachClass bool
+	DigitsOnly       bool
+	MinDigits        int
+	NoRepeats        bool
+	NoSequences      bool
+	RejectCommon     bool
+	CustomCharset    string
+	ExcludeChars     string
+	Count            int
+
+	Passphrase bool
+	Words      int
+	Separator  string
+
+	Token      string // "hex" or "base64"; empty means password mode
+	TokenBytes int
+
+	ShowEntropy  bool
+	ShowStrength bool
+	Breakdown    bool
+	JSON         bool
 }
 
 // ParseFlags registers and parses command-line flags, returning a Config.
@@ -35,9 +58,40 @@ func ParseFlags(fs *flag.FlagSet, args []string) Config {
 	fs.BoolVar(&cfg.UseSymbols, "symbols", false, "Include special symbols")
 	fs.BoolVar(&cfg.UseSymbols, "s", false, "Include symbols (shorthand)")
 
+	fs.BoolVar(&cfg.ExcludeAmbiguous, "no-ambiguous", false, "Exclude ambiguous characters (l, I, 1, O, 0)")
+	fs.BoolVar(&cfg.ExcludeAmbiguous, "a", false, "Exclude ambiguous characters (shorthand)")
+
+	fs.BoolVar(&cfg.RequireEachClass, "require-all", false, "Guarantee at least one character from each enabled class")
+	fs.BoolVar(&cfg.RequireEachClass, "r", false, "Require each character class (shorthand)")
+
+	fs.IntVar(&cfg.MinDigits, "min-digits", 0, "Minimum number of digits (requires -n)")
+
+	fs.BoolVar(&cfg.NoRepeats, "no-repeats", false, "Never repeat the same character twice in a row")
+	fs.BoolVar(&cfg.NoSequences, "no-sequences", false, "Forbid runs like abc or 321")
+	fs.BoolVar(&cfg.RejectCommon, "no-common", false, "Never output a well-known common password")
+
+	fs.BoolVar(&cfg.DigitsOnly, "pin", false, "Generate a numeric PIN (digits only)")
+
+	fs.StringVar(&cfg.CustomCharset, "charset", "", "Use exactly this set of characters (overrides -n/-s/-a)")
+
+	fs.StringVar(&cfg.ExcludeChars, "exclude", "", "Characters to never use (e.g. '$`\"')")
+	fs.StringVar(&cfg.ExcludeChars, "x", "", "Characters to exclude (shorthand)")
+
 	fs.IntVar(&cfg.Count, "count", 1, "Number of passwords to generate")
 	fs.IntVar(&cfg.Count, "c", 1, "Number of passwords (shorthand)")
 
+	fs.BoolVar(&cfg.Passphrase, "passphrase", false, "Generate a passphrase of random words instead")
+	fs.IntVar(&cfg.Words, "words", 4, "Number of words in a passphrase")
+	fs.StringVar(&cfg.Separator, "separator", "-", "Separator between passphrase words")
+
+	fs.StringVar(&cfg.Token, "token", "", "Generate a random token instead: hex or base64")
+	fs.IntVar(&cfg.TokenBytes, "bytes", 32, "Number of random bytes in a token")
+
+	fs.BoolVar(&cfg.ShowEntropy, "show-entropy", false, "Print password entropy in bits to stderr")
+	fs.BoolVar(&cfg.ShowStrength, "strength", false, "Print a strength label next to each password")
+	fs.BoolVar(&cfg.Breakdown, "breakdown", false, "Print per-class character counts next to each password")
+	fs.BoolVar(&cfg.JSON, "json", false, "Print passwords as a JSON array with entropy")
+
 	_ = fs.Parse(args)
 	return cfg
 }
@@ -46,7 +100,7 @@ func ParseFlags(fs *flag.FlagSet, args []string) Config {
 // The reader/writer parameters allow testing without real stdin/stdout.
 func RunInteractive(r io.Reader, w io.Writer) Config {
 	scanner := bufio.NewScanner(r)
-	cfg := Config{Length: 12, Count: 1}
+	cfg := Config{Length: 12, Count: 1, Words: 4, Separator: "-", TokenBytes: 32}
 
 	fmt.Fprintln(w, "=== Password Generator (interactive mode) ===")
 	fmt.Fprintln(w)
@@ -89,26 +143,124 @@ func parseYesNo(s string) bool {
 	return s == "y" || s == "yes"
 }
 
-// Run generates one or more passwords based on the config.
+// Options converts the CLI config into generator options.
+func (cfg Config) Options() generator.Options {
+	return generator.Options{
+		Length:           cfg.Length,
+		UseDigits:        cfg.UseDigits,
+		UseSymbols:       cfg.UseSymbols,
+		ExcludeAmbiguous: cfg.ExcludeAmbiguous,
+		RequireEachClass: cfg.RequireEachClass,
+		DigitsOnly:       cfg.DigitsOnly,
+		MinDigits:        cfg.MinDigits,
+		NoRepeats:        cfg.NoRepeats,
+		NoSequences:      cfg.NoSequences,
+		RejectCommon:     cfg.RejectCommon,
+		CustomCharset:    cfg.CustomCharset,
+		ExcludeChars:     cfg.ExcludeChars,
+	}
+}
+
+// Run generates one or more passwords based on the config. Passwords within
+// one run are guaranteed to be unique.
 func Run(cfg Config) ([]string, error) {
 	if cfg.Count < 1 {
 		cfg.Count = 1
 	}
-	opts := generator.Options{
-		Length:     cfg.Length,
-		UseDigits:  cfg.UseDigits,
-		UseSymbols: cfg.UseSymbols,
+	if cfg.Token != "" {
+		return runToken(cfg)
+	}
+	if cfg.Passphrase {
+		return runPassphrase(cfg)
+	}
+
+	return generator.GenerateBatch(cfg.Count, cfg.Options())
+}
+
+// runPassphrase generates cfg.Count passphrases from the built-in wordlist.
+func runPassphrase(cfg Config) ([]string, error) {
+	wordlist := generator.DefaultWordlist()
+
+	phrases := make([]string, 0, cfg.Count)
+	for i := 0; i < cfg.Count; i++ {
+		p, err := generator.GeneratePassphrase(cfg.Words, cfg.Separator, wordlist)
+		if err != nil {
+			return nil, err
+		}
+		phrases = append(phrases, p)
+	}
+	return phrases, nil
+}
+
+// runToken generates cfg.Count hex or base64 tokens of cfg.TokenBytes bytes.
+func runToken(cfg Config) ([]string, error) {
+	var gen func(int) (string, error)
+	switch cfg.Token {
+	case "hex":
+		gen = generator.GenerateHexToken
+	case "base64":
+		gen = generator.GenerateBase64Token
+	default:
+		return nil, fmt.Errorf("unknown token format %q (want hex or base64)", cfg.Token)
 	}
 
-	passwords := make([]string, 0, cfg.Count)
+	tokens := make([]string, 0, cfg.Count)
 	for i := 0; i < cfg.Count; i++ {
-		pw, err := generator.Generate(opts)
+		t, err := gen(cfg.TokenBytes)
 		if err != nil {
 			return nil, err
 		}
-		passwords = append(passwords, pw)
+		tokens = append(tokens, t)
 	}
-	return passwords, nil
+	return tokens, nil
+}
+
+// formatLine renders one password for plain output, appending the strength
+// label and class breakdown as tab-separated columns when requested.
+func formatLine(cfg Config, pw string) string {
+	cols := []string{pw}
+	if cfg.ShowStrength {
+		cols = append(cols, generator.Strength(pw))
+	}
+	if cfg.Breakdown {
+		c := generator.Classify(pw)
+		cols = append(cols, fmt.Sprintf("lower=%d upper=%d digits=%d symbols=%d",
+			c[generator.ClassLowercase], c[generator.ClassUppercase],
+			c[generator.ClassDigits], c[generator.ClassSymbols]))
+	}
+	return strings.Join(cols, "\t")
+}
+
+// Entropy returns the theoretical strength in bits of each generated value.
+func (cfg Config) Entropy() float64 {
+	if cfg.Token != "" {
+		return float64(8 * cfg.TokenBytes)
+	}
+	if cfg.Passphrase {
+		return float64(cfg.Words) * math.Log2(float64(len(generator.DefaultWordlist())))
+	}
+	return generator.Entropy(cfg.Options())
+}
+
+// passwordEntry is one element of the --json output.
+type passwordEntry struct {
+	Password string  `json:"password"`
+	Entropy  float64 `json:"entropy"`
+}
+
+// WriteJSON writes passwords to w as an indented JSON array, each entry
+// annotated with the given entropy (rounded to two decimals).
+func WriteJSON(w io.Writer, passwords []string, entropy float64) error {
+	entropy = math.Round(entropy*100) / 100
+
+	entries := make([]passwordEntry, 0, len(passwords))
+	for _, pw := range passwords {
+		entries = append(entries, passwordEntry{Password: pw, Entropy: entropy})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
 }
 
 func main() {
@@ -127,7 +279,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	if cfg.JSON {
+		if err := WriteJSON(os.Stdout, passwords, cfg.Entropy()); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	for _, pw := range passwords {
-		fmt.Println(pw)
+		fmt.Println(formatLine(cfg, pw))
+	}
+
+	// Entropy goes to stderr so piping passwords elsewhere stays clean.
+	if cfg.ShowEntropy {
+		fmt.Fprintf(os.Stderr, "entropy: %.1f bits\n", cfg.Entropy())
 	}
 }
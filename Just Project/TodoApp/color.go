@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// ANSI escape sequences used to highlight table rows.
+const (
+	ansiReset    = "\x1b[0m"
+	ansiRed      = "\x1b[31m"
+	ansiDimGreen = "\x1b[2;32m"
+)
+
+// useColor controls whether printTodos colors its output. main sets it
+// from the --color flag.
+var useColor bool
+
+// colorize wraps s in the given ANSI code when on is true.
+func colorize(s, code string, on bool) string {
+	if !on {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// colorEnabled decides whether to use color for mode "always", "never" or
+// "auto". In auto mode color is used only when stdout is a terminal and the
+// NO_COLOR environment variable (https://no-color.org) is not set.
+func colorEnabled(mode string) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto", "":
+		if os.Getenv("NO_COLOR") != "" {
+			return false, nil
+		}
+		info, err := os.Stdout.Stat()
+		return err == nil && info.Mode()&os.ModeCharDevice != 0, nil
+	default:
+		return false, fmt.Errorf("invalid color mode %q, expected auto, always or never", mode)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTableColor(t *testing.T) {
+	past := time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local)
+	todos := []Todo{
+		{ID: 1, Title: "finished", Done: true},
+		{ID: 2, Title: "late", Due: &past},
+		{ID: 3, Title: "plain"},
+	}
+
+	var on bytes.Buffer
+	writeTable(&on, todos, true)
+	lines := strings.Split(on.String(), "\n")
+	if !strings.HasPrefix(lines[2], ansiDimGreen) || !strings.HasSuffix(lines[2], ansiReset) {
+		t.Errorf("expected done row in dim green, got %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], ansiRed) {
+		t.Errorf("expected overdue row in red, got %q", lines[3])
+	}
+	if strings.Contains(lines[4], "\x1b[") {
+		t.Errorf("expected plain row without color, got %q", lines[4])
+	}
+
+	var off bytes.Buffer
+	writeTable(&off, todos, false)
+	if strings.Contains(off.String(), "\x1b[") {
+		t.Errorf("expected no escape codes with color off, got %q", off.String())
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if on, err := colorEnabled("auto"); err != nil || on {
+		t.Errorf("expected NO_COLOR to disable auto color, got %v (err %v)", on, err)
+	}
+	if on, _ := colorEnabled("always"); !on {
+		t.Error("expected always to force color on")
+	}
+	if on, _ := colorEnabled("never"); on {
+		t.Error("expected never to turn color off")
+	}
+	if _, err := colorEnabled("rainbow"); err == nil {
+		t.Error("expected error for invalid mode")
+	}
+}
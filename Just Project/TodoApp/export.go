@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader lists the columns written by ExportCSV.
+var csvHeader = []string{"id", "title", "done", "created", "due"}
+
+// ExportCSV writes all todos to w as CSV with a header row. Titles are
+// quoted as needed by encoding/csv; due is empty when not set.
+func (s *Store) ExportCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, t := range s.Todos() {
+		due := ""
+		if t.Due != nil {
+			due = t.Due.Format(dateLayout)
+		}
+		record := []string{
+			strconv.Itoa(t.ID),
+			t.Title,
+			strconv.FormatBool(t.Done),
+			t.CreatedAt.Format(time.RFC3339),
+			due,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportMarkdown writes todos as a GitHub-style checklist, one
+// "- [ ] #<id> <title>" line per todo ("[x]" for done ones).
+func (s *Store) ExportMarkdown(w io.Writer) error {
+	for _, t := range s.Todos() {
+		box := "[ ]"
+		if t.Done {
+			box = "[x]"
+		}
+		if _, err := fmt.Fprintf(w, "- %s #%d %s\n", box, t.ID, t.Title); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTodosJSON writes todos to w as an indented JSON array, using the
+// same field names as the data file. An empty list is written as [].
+func writeTodosJSON(w io.Writer, todos []Todo) error {
+	if todos == nil {
+		todos = []Todo{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(todos)
+}
+
+// ImportCSV reads todos from CSV rows of title, done and due (YYYY-MM-DD)
+// and appends them with fresh IDs. done and due may be empty or missing.
+// A header row is optional; when present, columns are matched by name, so
+// files written by ExportCSV can be imported back. Malformed rows are
+// skipped and reported together in the returned error; the count is the
+// number of todos actually added.
+func (s *Store) ImportCSV(r io.Reader) (int, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cols := map[string]int{"title": 0, "done": 1, "due": 2}
+
+	var (
+		parsed []Todo
+		errs   []error
+		first  = true
+	)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				errs = append(errs, err)
+				continue
+			}
+			return 0, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		if first {
+			first = false
+			if header := headerColumns(record); header != nil {
+				cols = header
+				continue
+			}
+		}
+
+		t, err := parseCSVRow(record, cols)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		parsed = append(parsed, t)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range parsed {
+		added := s.add(t.Title, 0)
+		i := len(s.todos) - 1
+		s.todos[i].Due = t.Due
+		if t.Done {
+			s.todos[i].Done = true
+			s.todos[i].CompletedAt = &added.CreatedAt
+		}
+	}
+	return len(parsed), errors.Join(errs...)
+}
+
+// headerColumns maps column names to positions if record is a header row
+// (one that has a "title" column), and returns nil otherwise.
+func headerColumns(record []string) map[string]int {
+	cols := make(map[string]int)
+	for i, name := range record {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := cols["title"]; !ok {
+		return nil
+	}
+	return cols
+}
+
+// parseCSVRow builds a Todo from one CSV record using the column positions
+// in cols. Only the title is required.
+func parseCSVRow(record []string, cols map[string]int) (Todo, error) {
+	field := func(name string) string {
+		i, ok := cols[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	t := Todo{Title: field("title")}
+	if t.Title == "" {
+		return Todo{}, fmt.Errorf("missing title")
+	}
+	if v := field("done"); v != "" {
+		done, err := strconv.ParseBool(v)
+		if err != nil {
+			return Todo{}, fmt.Errorf("invalid done value %q", v)
+		}
+		t.Done = done
+	}
+	if v := field("due"); v != "" {
+		due, err := parseDue(v)
+		if err != nil {
+			return Todo{}, err
+		}
+		t.Due = &due
+	}
+	return t, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportCSV(t *testing.T) {
+	created := time.Date(2026, 2, 1, 9, 30, 0, 0, time.UTC)
+	due := time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC)
+	s := NewStore([]Todo{
+		{ID: 1, Title: "Buy milk, eggs", Done: true, CreatedAt: created},
+		{ID: 2, Title: `Say "hi"`, CreatedAt: created, Due: &due},
+	})
+
+	var buf bytes.Buffer
+	if err := s.ExportCSV(&buf); err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("exported CSV does not parse: %v", err)
+	}
+
+	want := [][]string{
+		{"id", "title", "done", "created", "due"},
+		{"1", "Buy milk, eggs", "true", "2026-02-01T09:30:00Z", ""},
+		{"2", `Say "hi"`, "false", "2026-02-01T09:30:00Z", "2026-02-10"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d: %v", len(want), len(rows), rows)
+	}
+	for i := range want {
+		for j := range want[i] {
+			if rows[i][j] != want[i][j] {
+				t.Errorf("row %d col %d: expected %q, got %q", i, j, want[i][j], rows[i][j])
+			}
+		}
+	}
+}
+
+func TestExportMarkdown(t *testing.T) {
+	s := NewStore([]Todo{
+		{ID: 1, Title: "Write README"},
+		{ID: 3, Title: "Fix bug", Done: true},
+	})
+
+	var buf bytes.Buffer
+	if err := s.ExportMarkdown(&buf); err != nil {
+		t.Fatalf("ExportMarkdown: %v", err)
+	}
+
+	want := "- [ ] #1 Write README\n- [x] #3 Fix bug\n"
+	if buf.String() != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, buf.String())
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	var s Store
+	s.Add("existing")
+
+	in := "title,done,due\n" +
+		"Buy milk,false,2026-04-01\n" +
+		"\"Call mom, dad\",true,\n" +
+		",false,\n" + // missing title
+		"Bad date,false,01.04.2026\n" +
+		"Plain\n"
+
+	n, err := s.ImportCSV(strings.NewReader(in))
+	if n != 3 {
+		t.Errorf("expected 3 imported, got %d", n)
+	}
+	if err == nil || !strings.Contains(err.Error(), "line 4") || !strings.Contains(err.Error(), "line 5") {
+		t.Errorf("expected errors for lines 4 and 5, got %v", err)
+	}
+
+	todos := s.Todos()
+	if got := ids(todos); len(got) != 4 || got[3] != 4 {
+		t.Fatalf("expected fresh IDs [1 2 3 4], got %v", got)
+	}
+	if todos[1].Title != "Buy milk" || todos[1].Due == nil || todos[1].Due.Format(dateLayout) != "2026-04-01" {
+		t.Errorf("unexpected first import: %+v", todos[1])
+	}
+	if todos[2].Title != "Call mom, dad" || !todos[2].Done || todos[2].CompletedAt == nil {
+		t.Errorf("unexpected second import: %+v", todos[2])
+	}
+	if todos[3].Title != "Plain" || todos[3].Done || todos[3].Due != nil {
+		t.Errorf("unexpected third import: %+v", todos[3])
+	}
+}
+
+func TestImportCSVRoundTrip(t *testing.T) {
+	created := time.Date(2026, 2, 1, 9, 30, 0, 0, time.UTC)
+	src := NewStore([]Todo{
+		{ID: 7, Title: "exported", Done: true, CreatedAt: created},
+	})
+	var buf bytes.Buffer
+	if err := src.ExportCSV(&buf); err != nil {
+		t.Fatalf("ExportCSV: %v", err)
+	}
+
+	var dst Store
+	if n, err := dst.ImportCSV(&buf); n != 1 || err != nil {
+		t.Fatalf("expected 1 todo and no error, got %d, %v", n, err)
+	}
+	if got := dst.Todos()[0]; got.ID != 1 || got.Title != "exported" || !got.Done {
+		t.Errorf("unexpected round-tripped todo: %+v", got)
+	}
+}
+
+func TestWriteTodosJSON(t *testing.T) {
+	var s Store
+	s.Add("Write README")
+	s.Add("Fix bug")
+	_ = s.Complete(2)
+
+	var buf bytes.Buffer
+	if err := writeTodosJSON(&buf, s.Filter(true)); err != nil {
+		t.Fatalf("writeTodosJSON: %v", err)
+	}
+	var got []Todo
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got) != 1 || got[0].ID != 2 || got[0].Title != "Fix bug" || !got[0].Done {
+		t.Errorf("unexpected todos: %+v", got)
+	}
+
+	buf.Reset()
+	if err := writeTodosJSON(&buf, nil); err != nil {
+		t.Fatalf("writeTodosJSON: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("expected [] for no todos, got %q", buf.String())
+	}
+}
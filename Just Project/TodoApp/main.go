@@ -4,17 +4,53 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 )
 
-const dataFile = "todos.json"
+const (
+	// defaultDataFile is used when neither --file nor TODO_FILE is set.
+	defaultDataFile = "todos.json"
+	// dataFileEnv names the environment variable that overrides the data file.
+	dataFileEnv = "TODO_FILE"
+)
+
+// resolveDataFile picks the data file path: the --file flag wins, then the
+// TODO_FILE environment variable, then the default.
+func resolveDataFile(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if env := os.Getenv(dataFileEnv); env != "" {
+		return env
+	}
+	return defaultDataFile
+}
 
 func main() {
-	addFlag := flag.String("add", "", "Add a new todo with the given title")
+	addFlag := flag.String("add", "", "Add a new todo with the given title (separate several with ';' or newlines)")
+	dueFlag := flag.String("due", "", "Due date for --add (YYYY-MM-DD)")
+	priorityFlag := flag.String("priority", "", "Priority for --add: low, med or high")
+	recurFlag := flag.String("recur", "", "Recurrence for --add: daily, weekly or none")
+	tagsFlag := flag.String("tags", "", "Comma-separated tags for --add")
+	parentFlag := flag.Int("parent", 0, "Parent todo ID for --add, making it a subtask")
 	listFlag := flag.Bool("list", false, "List all todos")
-	doneFlag := flag.Int("done", 0, "Mark a todo as done by ID")
-	deleteFlag := flag.Int("delete", 0, "Delete a todo by ID")
+	pendingFlag := flag.Bool("pending", false, "List only pending todos")
+	completedFlag := flag.Bool("completed", false, "List only completed todos")
+	jsonFlag := flag.Bool("json", false, "Print listed todos as JSON instead of a table")
+	statsFlag := flag.Bool("stats", false, "Show total, done, pending and overdue counts")
+	doneFlag := flag.String("done", "", "Mark a todo as done by ID or UID")
+	undoneFlag := flag.String("undone", "", "Mark a completed todo as not done by ID or UID")
+	deleteFlag := flag.String("delete", "", "Delete a todo by ID or UID")
+	cascadeFlag := flag.Bool("cascade", false, "With --delete, also delete the todo's subtasks")
+	clearDoneFlag := flag.Bool("clear-done", false, "Delete all completed todos")
+	archiveFlag := flag.Bool("archive", false, "Move all completed todos to the archive file")
+	exportCSVFlag := flag.String("export-csv", "", "Export all todos to a CSV file at the given path")
+	importCSVFlag := flag.String("import-csv", "", "Import todos from a CSV file (title, done, due)")
+	exportMDFlag := flag.Bool("export-md", false, "Print all todos as a Markdown checklist")
 	interactiveFlag := flag.Bool("interactive", false, "Start interactive REPL mode")
 	flag.BoolVar(interactiveFlag, "i", false, "Start interactive REPL mode (shorthand)")
+	colorFlag := flag.String("color", "auto", "Color the todo table: auto, always or never (auto honors NO_COLOR)")
+	fileFlag := flag.String("file", "", "Path to the todo data file (default $TODO_FILE or todos.json)")
 
 	flag.Parse()
 
@@ -23,20 +59,48 @@ func main() {
 		fmt.Fprintln(os.Stderr, "Todo CLI — manage your tasks from the terminal")
 		fmt.Fprintln(os.Stderr, "Usage:")
 		fmt.Fprintln(os.Stderr, "  go run . --add \"task title\"   Add a new todo")
+		fmt.Fprintln(os.Stderr, "  go run . --add \"a; b; c\"      Add several todos at once")
+		fmt.Fprintln(os.Stderr, "           [--due 2006-01-02]   ...with a due date")
+		fmt.Fprintln(os.Stderr, "           [--priority high]    ...with a priority (low/med/high)")
+		fmt.Fprintln(os.Stderr, "           [--recur daily]      ...repeating daily or weekly")
+		fmt.Fprintln(os.Stderr, "           [--tags work,home]   ...with comma-separated tags")
+		fmt.Fprintln(os.Stderr, "           [--parent <id>]      ...as a subtask of another todo")
 		fmt.Fprintln(os.Stderr, "  go run . --list               List all todos")
-		fmt.Fprintln(os.Stderr, "  go run . --done <id>          Mark a todo as done")
-		fmt.Fprintln(os.Stderr, "  go run . --delete <id>        Delete a todo")
+		fmt.Fprintln(os.Stderr, "  go run . --pending            List only pending todos")
+		fmt.Fprintln(os.Stderr, "  go run . --completed          List only completed todos")
+		fmt.Fprintln(os.Stderr, "           [--json]             ...any listing as JSON")
+		fmt.Fprintln(os.Stderr, "  go run . --stats              Show todo counts")
+		fmt.Fprintln(os.Stderr, "  go run . --done <id|uid>      Mark a todo as done")
+		fmt.Fprintln(os.Stderr, "  go run . --undone <id|uid>    Mark a todo as not done")
+		fmt.Fprintln(os.Stderr, "  go run . --delete <id|uid>    Delete a todo")
+		fmt.Fprintln(os.Stderr, "           [--cascade]          ...together with its subtasks")
+		fmt.Fprintln(os.Stderr, "  go run . --clear-done         Delete all completed todos")
+		fmt.Fprintln(os.Stderr, "  go run . --archive            Move completed todos to todos.archive.json")
+		fmt.Fprintln(os.Stderr, "  go run . --export-csv <path>  Export todos to CSV")
+		fmt.Fprintln(os.Stderr, "  go run . --export-md          Print todos as a Markdown checklist")
+		fmt.Fprintln(os.Stderr, "  go run . --import-csv <path>  Import todos from CSV (title, done, due)")
 		fmt.Fprintln(os.Stderr, "  go run . --interactive        Start interactive REPL mode")
+		fmt.Fprintln(os.Stderr, "  --file <path>                 Use another data file (or set TODO_FILE)")
+		fmt.Fprintln(os.Stderr, "  --color auto|always|never     Color the table (auto: only on a terminal, off with NO_COLOR)")
 		os.Exit(1)
 	}
 
+	path := resolveDataFile(*fileFlag)
+
+	color, err := colorEnabled(*colorFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	useColor = color
+
 	// Interactive REPL — runs until the user types 'exit'
 	if *interactiveFlag {
-		runREPL()
+		runREPL(path)
 		return
 	}
 
-	store, err := load(dataFile)
+	store, err := load(path)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error loading todos:", err)
 		os.Exit(1)
@@ -44,68 +108,293 @@ func main() {
 
 	switch {
 	case *addFlag != "":
-		if err := runAdd(&store, *addFlag); err != nil {
-			fmt.Fprintln(os.Stderr, err)
+		opts := addOptions{}
+		if *dueFlag != "" {
+			due, err := parseDue(*dueFlag)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			opts.Due = &due
+		}
+		if *priorityFlag != "" {
+			p, err := parsePriority(*priorityFlag)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			opts.Priority = p
+		}
+		if *recurFlag != "" {
+			r, err := parseRecur(*recurFlag)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			opts.Recur = r
+		}
+		opts.Tags = parseTags(*tagsFlag)
+		opts.Parent = *parentFlag
+		titles := splitTitles(*addFlag)
+		if len(titles) == 0 {
+			fmt.Fprintln(os.Stderr, "title cannot be empty")
+			os.Exit(1)
+		}
+		for _, title := range titles {
+			if err := runAdd(store, title, opts); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+	case *listFlag || *pendingFlag || *completedFlag || *jsonFlag:
+		view := listAll
+		if *pendingFlag {
+			view = listPending
+		} else if *completedFlag {
+			view = listDone
+		}
+		if err := runList(store, view, *jsonFlag); err != nil {
+			fmt.Fprintln(os.Stderr, "Error listing:", err)
+			os.Exit(1)
+		}
+		return
+	case *statsFlag:
+		printStats(store)
+		return
+	case *exportCSVFlag != "":
+		if err := runExportCSV(store, *exportCSVFlag); err != nil {
+			fmt.Fprintln(os.Stderr, "Error exporting:", err)
+			os.Exit(1)
+		}
+		return
+	case *importCSVFlag != "":
+		if err := runImportCSV(store, *importCSVFlag); err != nil {
+			fmt.Fprintln(os.Stderr, "Error importing:", err)
+			os.Exit(1)
+		}
+	case *exportMDFlag:
+		if err := store.ExportMarkdown(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "Error exporting:", err)
 			os.Exit(1)
 		}
-	case *listFlag:
-		store.Print()
 		return
-	case *doneFlag != 0:
-		if err := runDone(&store, *doneFlag); err != nil {
+	case *doneFlag != "":
+		id, err := store.Lookup(*doneFlag)
+		if err == nil {
+			err = runDone(store, id)
+		}
+		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
-	case *deleteFlag != 0:
-		if err := runDelete(&store, *deleteFlag); err != nil {
+	case *undoneFlag != "":
+		id, err := store.Lookup(*undoneFlag)
+		if err == nil {
+			err = runUndone(store, id)
+		}
+		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
+	case *deleteFlag != "":
+		id, err := store.Lookup(*deleteFlag)
+		if err == nil {
+			err = runDelete(store, id, *cascadeFlag)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case *archiveFlag:
+		// archive saves both files itself.
+		if err := runArchive(store, path); err != nil {
+			fmt.Fprintln(os.Stderr, "Error archiving:", err)
+			os.Exit(1)
+		}
+		return
+	case *clearDoneFlag:
+		runClearDone(store)
 	default:
 		fmt.Fprintln(os.Stderr, "No valid flag provided. Run with no flags for usage.")
 		os.Exit(1)
 	}
 
-	if err := save(dataFile, store); err != nil {
+	if err := save(path, store); err != nil {
 		fmt.Fprintln(os.Stderr, "Error saving todos:", err)
 		os.Exit(1)
 	}
 }
 
-func runAdd(store *Store, title string) error {
+// addOptions carries the optional attributes that can be set when adding a todo.
+type addOptions struct {
+	Due      *time.Time
+	Priority Priority
+	Tags     []string
+	Parent   int
+	Recur    Recurrence
+}
+
+func runAdd(store *Store, title string, opts addOptions) error {
 	if title == "" {
 		return fmt.Errorf("title cannot be empty")
 	}
-	todo := store.Add(title)
+	var todo Todo
+	if opts.Parent != 0 {
+		var err error
+		if todo, err = store.AddSub(opts.Parent, title); err != nil {
+			return err
+		}
+	} else {
+		todo = store.Add(title)
+	}
+	if opts.Due != nil {
+		_ = store.SetDue(todo.ID, opts.Due)
+	}
+	if opts.Priority != "" {
+		_ = store.SetPriority(todo.ID, string(opts.Priority))
+	}
+	if len(opts.Tags) > 0 {
+		_ = store.SetTags(todo.ID, opts.Tags)
+	}
+	if opts.Recur != RecurNone {
+		_ = store.SetRecur(todo.ID, string(opts.Recur))
+	}
 	fmt.Printf("Added: [%d] %s\n", todo.ID, todo.Title)
 	return nil
 }
 
 func runDone(store *Store, id int) error {
-	if err := store.Complete(id); err != nil {
+	next, err := store.CompleteNext(id)
+	if err != nil {
 		return err
 	}
-	for _, t := range *store {
-		if t.ID == id {
-			fmt.Printf("Done: [%d] %s\n", t.ID, t.Title)
-			return nil
-		}
+	if t, ok := store.Get(id); ok {
+		fmt.Printf("Done: [%d] %s\n", t.ID, t.Title)
+	}
+	if next != nil {
+		fmt.Printf("Next: [%d] %s due %s\n", next.ID, next.Title, next.Due.Format(dateLayout))
 	}
 	return nil
 }
 
-func runDelete(store *Store, id int) error {
+func runUndone(store *Store, id int) error {
+	if err := store.Uncomplete(id); err != nil {
+		return err
+	}
+	if t, ok := store.Get(id); ok {
+		fmt.Printf("Not done: [%d] %s\n", t.ID, t.Title)
+	}
+	return nil
+}
+
+func runDelete(store *Store, id int, cascade bool) error {
 	// Capture title before deletion for output
-	title := ""
-	for _, t := range *store {
-		if t.ID == id {
-			title = t.Title
-			break
+	t, _ := store.Get(id)
+	if !cascade {
+		if err := store.Delete(id); err != nil {
+			return err
 		}
+		fmt.Printf("Deleted: [%d] %s\n", id, t.Title)
+		return nil
 	}
-	if err := store.Delete(id); err != nil {
+	n, err := store.DeleteTree(id)
+	if err != nil {
 		return err
 	}
-	fmt.Printf("Deleted: [%d] %s\n", id, title)
+	fmt.Printf("Deleted: [%d] %s (%d subtask(s))\n", id, t.Title, n-1)
+	return nil
+}
+
+// listView selects which todos a listing shows.
+type listView int
+
+const (
+	listAll listView = iota
+	listPending
+	listDone
+)
+
+// runList prints the todos selected by view, as a table or as JSON.
+func runList(store *Store, view listView, asJSON bool) error {
+	var todos []Todo
+	switch view {
+	case listPending:
+		todos = store.Filter(false)
+	case listDone:
+		todos = store.Filter(true)
+	default:
+		if !asJSON {
+			store.Print()
+			return nil
+		}
+		todos = store.Todos()
+	}
+	if asJSON {
+		return writeTodosJSON(os.Stdout, todos)
+	}
+	printFiltered(todos)
+	return nil
+}
+
+// printFiltered prints the given subset of todos, or a note when it's empty.
+func printFiltered(todos []Todo) {
+	if len(todos) == 0 {
+		fmt.Println("No matching todos.")
+		return
+	}
+	printTodos(todos)
+}
+
+// printStats prints the todo counts on one line.
+func printStats(store *Store) {
+	total, done, pending, overdue := store.Stats()
+	fmt.Printf("Total: %d  Done: %d  Pending: %d  Overdue: %d\n", total, done, pending, overdue)
+}
+
+func runArchive(store *Store, path string) error {
+	n, err := archive(path, store)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Archived %d completed todo(s) to %s\n", n, archivePath(path))
+	return nil
+}
+
+func runClearDone(store *Store) {
+	n := store.ClearCompleted()
+	fmt.Printf("Cleared %d completed todo(s)\n", n)
+}
+
+func runExportCSV(store *Store, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := store.ExportCSV(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d todo(s) to %s\n", store.Len(), path)
+	return nil
+}
+
+// runImportCSV imports todos from the CSV file at path. Rows that fail to
+// parse are reported on stderr; the rest are still imported.
+func runImportCSV(store *Store, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	n, err := store.ImportCSV(f)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Skipped rows:")
+		fmt.Fprintln(os.Stderr, err)
+	}
+	fmt.Printf("Imported %d todo(s) from %s\n", n, path)
 	return nil
 }
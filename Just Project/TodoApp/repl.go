@@ -4,13 +4,16 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// runREPL starts an interactive command loop, persisting changes after each command.
-func runREPL() {
-	store, err := load(dataFile)
+// runREPL starts an interactive command loop, persisting changes to path after each command.
+func runREPL(path string) {
+	store, err := load(path)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, "Error loading todos:", err)
 		os.Exit(1)
@@ -19,7 +22,9 @@ func runREPL() {
 	fmt.Println("Todo CLI — interactive mode (type 'help' for commands, 'exit' to quit)")
 	fmt.Println()
 
+	sess := newREPLSession(store, path)
 	scanner := bufio.NewScanner(os.Stdin)
+	sess.in = scanner
 	for {
 		fmt.Print("todo> ")
 		if !scanner.Scan() {
@@ -33,14 +38,181 @@ func runREPL() {
 			continue
 		}
 
-		if done := handleREPLCommand(&store, line); done {
+		if done := sess.execute(line); done {
 			break
 		}
 	}
 }
 
-// handleREPLCommand dispatches a single line of input. Returns true when user wants to quit.
-func handleREPLCommand(store *Store, line string) bool {
+// maxUndo is how many changes the REPL can undo.
+const maxUndo = 10
+
+const (
+	// historyFileName is the REPL history file, kept next to the data file.
+	historyFileName = ".todo_history"
+	// historyShown is how many recent commands "history" lists.
+	historyShown = 20
+)
+
+// replSession is the state of one interactive session.
+type replSession struct {
+	store *Store
+	path  string
+	undo  [][]Todo // snapshots taken before each change, most recent last
+
+	historyPath string   // empty disables persisting history
+	history     []string // commands from earlier sessions and this one, oldest first
+
+	in *bufio.Scanner // input for multi-line commands such as add-many
+}
+
+// newREPLSession returns a session for store saved at path, with command
+// history loaded from the history file next to it.
+func newREPLSession(store *Store, path string) *replSession {
+	r := &replSession{
+		store:       store,
+		path:        path,
+		historyPath: filepath.Join(filepath.Dir(path), historyFileName),
+	}
+	history, err := loadHistory(r.historyPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: cannot read history:", err)
+	}
+	r.history = history
+	return r
+}
+
+// loadHistory reads one command per line from path. A missing file is
+// an empty history.
+func loadHistory(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var history []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			history = append(history, line)
+		}
+	}
+	return history, nil
+}
+
+// record adds line to the history and appends it to the history file.
+func (r *replSession) record(line string) {
+	r.history = append(r.history, line)
+	if r.historyPath == "" {
+		return
+	}
+	f, err := os.OpenFile(r.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: cannot save history:", err)
+		return
+	}
+	defer f.Close()
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: cannot save history:", err)
+	}
+}
+
+// printHistory lists the most recent commands, numbered from the oldest shown.
+func (r *replSession) printHistory() {
+	start := len(r.history) - historyShown
+	if start < 0 {
+		start = 0
+	}
+	for i := start; i < len(r.history); i++ {
+		fmt.Printf("%4d  %s\n", i+1, r.history[i])
+	}
+}
+
+// execute runs one line of input, recording a snapshot whenever the command
+// changed the store so that "undo" can restore it. Returns true on quit.
+func (r *replSession) execute(line string) bool {
+	line = strings.TrimSpace(line)
+	r.record(line)
+
+	switch strings.ToLower(line) {
+	case "undo":
+		r.undoLast()
+		return false
+	case "history":
+		r.printHistory()
+		return false
+	}
+
+	before := r.store.Todos()
+	quit := false
+	if strings.ToLower(line) == "add-many" {
+		r.addMany()
+	} else {
+		quit = handleREPLCommand(r.store, r.path, line)
+	}
+	if !reflect.DeepEqual(before, r.store.Todos()) {
+		r.undo = append(r.undo, before)
+		if len(r.undo) > maxUndo {
+			r.undo = r.undo[1:]
+		}
+	}
+	return quit
+}
+
+// addMany reads titles one per line until a blank line or end of input,
+// adds each as its own todo and saves once at the end.
+func (r *replSession) addMany() {
+	if r.in == nil {
+		fmt.Fprintln(os.Stderr, "Error: add-many needs interactive input")
+		return
+	}
+	fmt.Println("Enter one title per line, blank line to finish:")
+	var titles []string
+	for {
+		fmt.Print("... ")
+		if !r.in.Scan() {
+			break
+		}
+		title := strings.TrimSpace(r.in.Text())
+		if title == "" {
+			break
+		}
+		titles = append(titles, title)
+	}
+	if len(titles) == 0 {
+		fmt.Println("Nothing added.")
+		return
+	}
+	for _, title := range titles {
+		if err := runAdd(r.store, title, addOptions{}); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+	}
+	if err := save(r.path, r.store); err != nil {
+		fmt.Fprintln(os.Stderr, "Error saving:", err)
+	}
+}
+
+// undoLast restores the most recent snapshot and saves it.
+func (r *replSession) undoLast() {
+	if len(r.undo) == 0 {
+		fmt.Println("Nothing to undo.")
+		return
+	}
+	last := len(r.undo) - 1
+	r.store.replace(r.undo[last])
+	r.undo = r.undo[:last]
+
+	fmt.Println("Undone last change.")
+	if err := save(r.path, r.store); err != nil {
+		fmt.Fprintln(os.Stderr, "Error saving:", err)
+	}
+}
+
+// handleREPLCommand dispatches a single line of input, saving changes to path.
+// Returns true when user wants to quit.
+func handleREPLCommand(store *Store, path, line string) bool {
 	parts := strings.SplitN(line, " ", 2)
 	cmd := strings.ToLower(parts[0])
 	arg := ""
@@ -57,43 +229,275 @@ func handleREPLCommand(store *Store, line string) bool {
 		printREPLHelp()
 
 	case "list", "ls":
+		fields := strings.Fields(strings.ToLower(arg))
+		asJSON := len(fields) > 0 && fields[len(fields)-1] == "json"
+		if asJSON {
+			fields = fields[:len(fields)-1]
+		}
+		view := listAll
+		switch {
+		case len(fields) == 0:
+		case len(fields) == 1 && fields[0] == "pending":
+			view = listPending
+		case len(fields) == 1 && fields[0] == "done":
+			view = listDone
+		default:
+			fmt.Fprintln(os.Stderr, "Error: usage  list [pending|done] [json]")
+			return false
+		}
+		if err := runList(store, view, asJSON); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+
+	case "search", "find":
+		matches := store.Search(arg)
+		if len(matches) == 0 {
+			fmt.Println("No matching todos.")
+			return false
+		}
+		printTodos(matches)
+
+	case "tagged":
+		if arg == "" {
+			fmt.Fprintln(os.Stderr, "Error: usage  tagged <tag>")
+			return false
+		}
+		matches := store.FilterByTag(arg)
+		if len(matches) == 0 {
+			fmt.Println("No todos with that tag.")
+			return false
+		}
+		printTodos(matches)
+
+	case "stats":
+		printStats(store)
+
+	case "sort":
+		if err := store.Sort(arg); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return false
+		}
 		store.Print()
 
 	case "add":
-		arg = strings.Trim(arg, `"'`)
-		if err := runAdd(store, arg); err != nil {
+		titles := splitTitles(arg)
+		if len(titles) == 0 {
+			fmt.Fprintln(os.Stderr, "Error: title cannot be empty")
+			return false
+		}
+		for _, title := range titles {
+			if err := runAdd(store, strings.Trim(title, `"'`), addOptions{}); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				return false
+			}
+		}
+		if err := save(path, store); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving:", err)
+		}
+
+	case "sub":
+		parts := strings.SplitN(arg, " ", 2)
+		parent, err := strconv.Atoi(parts[0])
+		if err != nil || parent <= 0 || len(parts) < 2 {
+			fmt.Fprintln(os.Stderr, "Error: usage  sub <parent-id> <title>")
+			return false
+		}
+		title := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+		if err := runAdd(store, title, addOptions{Parent: parent}); err != nil {
 			fmt.Fprintln(os.Stderr, "Error:", err)
 			return false
 		}
-		if err := save(dataFile, *store); err != nil {
+		if err := save(path, store); err != nil {
 			fmt.Fprintln(os.Stderr, "Error saving:", err)
 		}
 
 	case "done":
-		id, err := strconv.Atoi(arg)
-		if err != nil || id <= 0 {
-			fmt.Fprintln(os.Stderr, "Error: provide a valid numeric ID, e.g.  done 2")
+		id, err := store.Lookup(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
 			return false
 		}
 		if err := runDone(store, id); err != nil {
 			fmt.Fprintln(os.Stderr, "Error:", err)
 			return false
 		}
-		if err := save(dataFile, *store); err != nil {
+		if err := save(path, store); err != nil {
 			fmt.Fprintln(os.Stderr, "Error saving:", err)
 		}
 
-	case "delete", "del", "rm":
-		id, err := strconv.Atoi(arg)
+	case "undone":
+		id, err := store.Lookup(arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return false
+		}
+		if err := runUndone(store, id); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return false
+		}
+		if err := save(path, store); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving:", err)
+		}
+
+	case "due":
+		fields := strings.Fields(arg)
+		if len(fields) != 2 {
+			fmt.Fprintln(os.Stderr, "Error: usage  due <id> <YYYY-MM-DD|none>")
+			return false
+		}
+		id, err := strconv.Atoi(fields[0])
 		if err != nil || id <= 0 {
-			fmt.Fprintln(os.Stderr, "Error: provide a valid numeric ID, e.g.  delete 2")
+			fmt.Fprintln(os.Stderr, "Error: provide a valid numeric ID, e.g.  due 2 2026-03-01")
 			return false
 		}
-		if err := runDelete(store, id); err != nil {
+		var due *time.Time
+		if fields[1] != "none" {
+			d, err := parseDue(fields[1])
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+				return false
+			}
+			due = &d
+		}
+		if err := store.SetDue(id, due); err != nil {
 			fmt.Fprintln(os.Stderr, "Error:", err)
 			return false
 		}
-		if err := save(dataFile, *store); err != nil {
+		fmt.Printf("Due date updated: [%d] %s\n", id, fields[1])
+		if err := save(path, store); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving:", err)
+		}
+
+	case "snooze":
+		fields := strings.Fields(arg)
+		if len(fields) != 2 {
+			fmt.Fprintln(os.Stderr, "Error: usage  snooze <id> <days>")
+			return false
+		}
+		id, err := strconv.Atoi(fields[0])
+		if err != nil || id <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: provide a valid numeric ID, e.g.  snooze 2 3")
+			return false
+		}
+		days, err := strconv.Atoi(fields[1])
+		if err != nil || days <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: provide a positive number of days, e.g.  snooze 2 3")
+			return false
+		}
+		if err := store.Postpone(id, time.Duration(days)*24*time.Hour); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return false
+		}
+		if t, ok := store.Get(id); ok {
+			fmt.Printf("Snoozed: [%d] due %s\n", id, t.Due.Format(dateLayout))
+		}
+		if err := save(path, store); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving:", err)
+		}
+
+	case "priority", "prio":
+		fields := strings.Fields(arg)
+		if len(fields) != 2 {
+			fmt.Fprintln(os.Stderr, "Error: usage  priority <id> <low|med|high>")
+			return false
+		}
+		id, err := strconv.Atoi(fields[0])
+		if err != nil || id <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: provide a valid numeric ID, e.g.  priority 2 high")
+			return false
+		}
+		if err := store.SetPriority(id, fields[1]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return false
+		}
+		fmt.Printf("Priority updated: [%d] %s\n", id, strings.ToLower(fields[1]))
+		if err := save(path, store); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving:", err)
+		}
+
+	case "recur":
+		fields := strings.Fields(arg)
+		if len(fields) != 2 {
+			fmt.Fprintln(os.Stderr, "Error: usage  recur <id> <daily|weekly|none>")
+			return false
+		}
+		id, err := strconv.Atoi(fields[0])
+		if err != nil || id <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: provide a valid numeric ID, e.g.  recur 2 weekly")
+			return false
+		}
+		if err := store.SetRecur(id, fields[1]); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return false
+		}
+		fmt.Printf("Recurrence updated: [%d] %s\n", id, strings.ToLower(fields[1]))
+		if err := save(path, store); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving:", err)
+		}
+
+	case "tag":
+		parts := strings.SplitN(arg, " ", 2)
+		id, err := strconv.Atoi(parts[0])
+		if err != nil || id <= 0 {
+			fmt.Fprintln(os.Stderr, "Error: provide a valid numeric ID, e.g.  tag 2 work,urgent")
+			return false
+		}
+		var tags []string
+		if len(parts) > 1 {
+			tags = parseTags(parts[1])
+		}
+		if err := store.SetTags(id, tags); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return false
+		}
+		fmt.Printf("Tags updated: [%d] %s\n", id, strings.Join(tags, ","))
+		if err := save(path, store); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving:", err)
+		}
+
+	case "delete", "del", "rm":
+		fields := strings.Fields(arg)
+		cascade := len(fields) == 2 && fields[1] == "cascade"
+		if len(fields) == 0 || len(fields) > 2 || (len(fields) == 2 && !cascade) {
+			fmt.Fprintln(os.Stderr, "Error: usage  delete <id> [cascade]")
+			return false
+		}
+		id, err := store.Lookup(fields[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return false
+		}
+		if err := runDelete(store, id, cascade); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			return false
+		}
+		if err := save(path, store); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving:", err)
+		}
+
+	case "export":
+		fields := strings.Fields(arg)
+		switch {
+		case len(fields) == 1 && fields[0] == "md":
+			if err := store.ExportMarkdown(os.Stdout); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			}
+		case len(fields) == 2 && fields[0] == "csv":
+			if err := runExportCSV(store, fields[1]); err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", err)
+			}
+		default:
+			fmt.Fprintln(os.Stderr, "Error: usage  export md  |  export csv <path>")
+		}
+
+	case "archive":
+		if err := runArchive(store, path); err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+
+	case "clear":
+		runClearDone(store)
+		if err := save(path, store); err != nil {
 			fmt.Fprintln(os.Stderr, "Error saving:", err)
 		}
 
@@ -106,10 +510,31 @@ func handleREPLCommand(store *Store, line string) bool {
 
 func printREPLHelp() {
 	fmt.Println("Commands:")
-	fmt.Println("  add <title>   Add a new todo")
+	fmt.Println("  add <title>   Add a new todo ('a; b; c' adds several)")
+	fmt.Println("  add-many      Add several todos, one title per line")
+	fmt.Println("  sub <id> <title>  Add a subtask under todo <id>")
 	fmt.Println("  list          List all todos")
+	fmt.Println("  list pending  List only pending todos")
+	fmt.Println("  list done     List only completed todos")
+	fmt.Println("  list ... json Print the listing as JSON")
+	fmt.Println("  search <text> List todos whose title contains text")
+	fmt.Println("  stats         Show total, done, pending and overdue counts")
+	fmt.Println("  sort <field>  Sort by id, title, created, done or priority")
+	fmt.Println("  tagged <tag>  List todos with the given tag")
 	fmt.Println("  done <id>     Mark a todo as done")
-	fmt.Println("  delete <id>   Delete a todo")
+	fmt.Println("  undone <id>   Mark a todo as not done")
+	fmt.Println("  due <id> <date>  Set due date (YYYY-MM-DD, or 'none' to clear)")
+	fmt.Println("  snooze <id> <days>  Push the due date forward by <days>")
+	fmt.Println("  priority <id> <level>  Set priority (low, med, high)")
+	fmt.Println("  recur <id> <every>  Repeat daily or weekly ('none' to stop)")
+	fmt.Println("  tag <id> <a,b> Replace tags (no tags clears them)")
+	fmt.Println("  delete <id> [cascade]  Delete a todo (cascade: with its subtasks)")
+	fmt.Println("  clear         Delete all completed todos")
+	fmt.Println("  archive       Move completed todos to the archive file")
+	fmt.Println("  export md     Print todos as a Markdown checklist")
+	fmt.Println("  export csv <path>  Export todos to CSV")
+	fmt.Println("  undo          Revert the last change")
+	fmt.Println("  history       Show recent commands (kept in .todo_history)")
 	fmt.Println("  help          Show this help")
 	fmt.Println("  exit          Quit the program")
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestSession returns a REPL session backed by a temp data file.
+func newTestSession(t *testing.T) *replSession {
+	t.Helper()
+	return newREPLSession(NewStore(nil), filepath.Join(t.TempDir(), "todos.json"))
+}
+
+func TestREPLUndoAdd(t *testing.T) {
+	sess := newTestSession(t)
+
+	sess.execute("add Buy milk")
+	if sess.store.Len() != 1 {
+		t.Fatalf("expected 1 todo after add, got %d", sess.store.Len())
+	}
+
+	sess.execute("undo")
+	if sess.store.Len() != 0 {
+		t.Fatalf("expected add to be undone, got %+v", sess.store.Todos())
+	}
+
+	// The restored state must also be persisted.
+	loaded, err := load(sess.path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Len() != 0 {
+		t.Errorf("expected empty file after undo, got %+v", loaded.Todos())
+	}
+}
+
+func TestREPLUndoIgnoresReadOnlyCommands(t *testing.T) {
+	sess := newTestSession(t)
+
+	sess.execute("add first")
+	sess.execute("done 1")
+	sess.execute("list")
+	sess.execute("done 99") // fails, nothing changes
+
+	sess.execute("undo")
+	if (sess.store.Todos())[0].Done {
+		t.Error("expected undo to revert 'done 1'")
+	}
+	sess.execute("undo")
+	if sess.store.Len() != 0 {
+		t.Errorf("expected second undo to revert the add, got %+v", sess.store.Todos())
+	}
+	if len(sess.undo) != 0 {
+		t.Errorf("expected empty undo stack, got %d entries", len(sess.undo))
+	}
+}
+
+func TestREPLHistoryPersisted(t *testing.T) {
+	sess := newTestSession(t)
+
+	for _, line := range []string{"add Buy milk", "  list  ", "done 1", "history"} {
+		sess.execute(line)
+	}
+
+	data, err := os.ReadFile(sess.historyPath)
+	if err != nil {
+		t.Fatalf("read history: %v", err)
+	}
+	want := "add Buy milk\nlist\ndone 1\nhistory\n"
+	if string(data) != want {
+		t.Errorf("expected history file %q, got %q", want, data)
+	}
+
+	// A new session in the same directory starts with the saved history.
+	next := newREPLSession(NewStore(nil), sess.path)
+	if len(next.history) != 4 || next.history[0] != "add Buy milk" {
+		t.Errorf("expected history to be loaded, got %q", next.history)
+	}
+}
+
+func TestREPLAddMany(t *testing.T) {
+	sess := newTestSession(t)
+	sess.in = bufio.NewScanner(strings.NewReader("Buy milk\n  Call mom  \nFix bike\n\nnot read\n"))
+
+	sess.execute("add-many")
+	if sess.store.Len() != 3 {
+		t.Fatalf("expected 3 todos, got %d: %+v", sess.store.Len(), sess.store.Todos())
+	}
+	if got := sess.store.Todos()[1].Title; got != "Call mom" {
+		t.Errorf("expected trimmed title, got %q", got)
+	}
+
+	sess.execute("add one; two ;;three")
+	if sess.store.Len() != 6 {
+		t.Fatalf("expected 6 todos after add with ';', got %d", sess.store.Len())
+	}
+
+	// The whole batch is a single undo step.
+	sess.execute("undo")
+	sess.execute("undo")
+	if sess.store.Len() != 0 {
+		t.Errorf("expected both batches undone, got %d todos", sess.store.Len())
+	}
+}
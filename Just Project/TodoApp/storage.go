@@ -3,30 +3,125 @@ package main
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 // load reads todos from a JSON file at path.
 // If the file does not exist, it returns an empty Store and no error.
-func load(path string) (Store, error) {
+// Todos from older files get UIDs assigned here, and the file is saved
+// right away so those UIDs stay the same on the next run.
+func load(path string) (*Store, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return Store{}, nil
+			return NewStore(nil), nil
 		}
 		return nil, err
 	}
-	var store Store
-	if err := json.Unmarshal(data, &store); err != nil {
+	var todos []Todo
+	if err := json.Unmarshal(data, &todos); err != nil {
 		return nil, err
 	}
+	backfilled := backfillUIDs(todos)
+	store := NewStore(todos)
+	if backfilled > 0 {
+		if err := save(path, store); err != nil {
+			return nil, err
+		}
+	}
 	return store, nil
 }
 
 // save writes todos to a JSON file at path with indentation.
-func save(path string, s Store) error {
-	data, err := json.MarshalIndent(s, "", "  ")
+func save(path string, s *Store) error {
+	todos := s.Todos()
+	if todos == nil {
+		todos = []Todo{}
+	}
+	return writeJSON(path, todos)
+}
+
+// archivePath returns the archive file that sits next to the data file,
+// e.g. todos.json -> todos.archive.json.
+func archivePath(path string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + ".archive" + ext
+}
+
+// archive moves all done todos from store into the archive file next to
+// path, appending to any existing archive, then saves the active list.
+// Both files are written atomically. Todos whose UID is already in the
+// archive are not appended again, so if saving the active list fails
+// after the archive was written, simply running archive again recovers
+// without duplicates. Returns how many todos were moved.
+func archive(path string, store *Store) (int, error) {
+	archived, err := load(archivePath(path))
+	if err != nil {
+		return 0, err
+	}
+
+	before := store.Todos()
+	done := store.TakeCompleted()
+	if len(done) == 0 {
+		return 0, nil
+	}
+
+	all := archived.Todos()
+	seen := make(map[string]bool, len(all))
+	for _, t := range all {
+		seen[t.UID] = true
+	}
+	for _, t := range done {
+		if !seen[t.UID] {
+			all = append(all, t)
+		}
+	}
+	if err := writeJSON(archivePath(path), all); err != nil {
+		store.replace(before)
+		return 0, err
+	}
+	if err := save(path, store); err != nil {
+		store.replace(before)
+		return 0, err
+	}
+	return len(done), nil
+}
+
+// writeJSON marshals v with indentation and atomically replaces path with
+// the result: the data goes to a temp file in the same directory, which is
+// then renamed over the target. A crash mid-write or a marshalling error
+// leaves the previous file intact. An existing file keeps its permissions.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+
+	perm := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
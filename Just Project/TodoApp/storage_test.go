@@ -0,0 +1,275 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveLoadDueDate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+
+	due, err := parseDue("2026-03-15")
+	if err != nil {
+		t.Fatalf("parseDue: %v", err)
+	}
+
+	var s Store
+	withDue := s.Add("with due date")
+	s.Add("without due date")
+	if err := s.SetDue(withDue.ID, &due); err != nil {
+		t.Fatalf("SetDue: %v", err)
+	}
+
+	if err := save(path, &s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	if loaded.Len() != 2 {
+		t.Fatalf("expected 2 todos, got %d", loaded.Len())
+	}
+	if loaded.Todos()[0].Due == nil || loaded.Todos()[0].Due.Format(dateLayout) != "2026-03-15" {
+		t.Errorf("expected due date 2026-03-15, got %v", loaded.Todos()[0].Due)
+	}
+	if loaded.Todos()[1].Due != nil {
+		t.Errorf("expected no due date, got %v", loaded.Todos()[1].Due)
+	}
+}
+
+func TestParseDueInvalid(t *testing.T) {
+	for _, in := range []string{"", "15.03.2026", "2026-13-01"} {
+		if _, err := parseDue(in); err == nil {
+			t.Errorf("parseDue(%q): expected error", in)
+		}
+	}
+}
+
+func TestSaveLoadPriority(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+
+	var s Store
+	todo := s.Add("ship release")
+	if err := s.SetPriority(todo.ID, "high"); err != nil {
+		t.Fatalf("SetPriority: %v", err)
+	}
+	if err := save(path, &s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Todos()[0].Priority != PriorityHigh {
+		t.Errorf("expected %q after reload, got %q", PriorityHigh, loaded.Todos()[0].Priority)
+	}
+}
+
+func TestLoadSaveCustomPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "work.json")
+
+	// A missing file loads as an empty store.
+	s, err := load(path)
+	if err != nil {
+		t.Fatalf("load missing file: %v", err)
+	}
+	if s.Len() != 0 {
+		t.Fatalf("expected empty store, got %d todos", s.Len())
+	}
+
+	s.Add("separate list")
+	if err := save(path, s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded, err := load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Len() != 1 || loaded.Todos()[0].Title != "separate list" {
+		t.Errorf("unexpected todos after reload: %+v", loaded.Todos())
+	}
+}
+
+func TestResolveDataFile(t *testing.T) {
+	t.Setenv(dataFileEnv, "")
+	if got := resolveDataFile(""); got != defaultDataFile {
+		t.Errorf("expected default %q, got %q", defaultDataFile, got)
+	}
+
+	t.Setenv(dataFileEnv, "/tmp/env.json")
+	if got := resolveDataFile(""); got != "/tmp/env.json" {
+		t.Errorf("expected env path, got %q", got)
+	}
+	if got := resolveDataFile("flag.json"); got != "flag.json" {
+		t.Errorf("expected flag to win over env, got %q", got)
+	}
+}
+
+func TestSaveLoadCompletedAt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+
+	var s Store
+	s.Add("done")
+	s.Add("pending")
+	_ = s.Complete(1)
+	if err := save(path, &s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	loaded, err := load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if loaded.Todos()[0].CompletedAt == nil || !loaded.Todos()[0].CompletedAt.Equal(*s.Todos()[0].CompletedAt) {
+		t.Errorf("expected completion time %v, got %v", s.Todos()[0].CompletedAt, loaded.Todos()[0].CompletedAt)
+	}
+	if loaded.Todos()[1].CompletedAt != nil {
+		t.Errorf("expected nil completion time, got %v", loaded.Todos()[1].CompletedAt)
+	}
+}
+
+func TestSaveIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todos.json")
+
+	var s Store
+	s.Add("first")
+	if err := save(path, &s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+	s.Add("second")
+	if err := save(path, &s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var todos []Todo
+	if err := json.Unmarshal(data, &todos); err != nil || len(todos) != 2 {
+		t.Fatalf("expected valid JSON with 2 todos, got %d (err %v)", len(todos), err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected permissions 0600 to be kept, got %v", info.Mode().Perm())
+	}
+
+	// A value that can't be marshalled must leave the file untouched.
+	if err := writeJSON(path, make(chan int)); err == nil {
+		t.Fatal("expected marshal error")
+	}
+	after, err := os.ReadFile(path)
+	if err != nil || string(after) != string(data) {
+		t.Errorf("expected file to be unchanged after failed save, got %q (err %v)", after, err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected no temp files left behind, got %d entries", len(entries))
+	}
+}
+
+func TestArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	if got := archivePath(path); filepath.Base(got) != "todos.archive.json" {
+		t.Fatalf("unexpected archive path %q", got)
+	}
+
+	var s Store
+	for _, title := range []string{"a", "b", "c"} {
+		s.Add(title)
+	}
+	_ = s.Complete(1)
+	if n, err := archive(path, &s); err != nil || n != 1 {
+		t.Fatalf("expected 1 archived, got %d (err %v)", n, err)
+	}
+
+	// A second run appends to the existing archive.
+	_ = s.Complete(3)
+	if n, err := archive(path, &s); err != nil || n != 1 {
+		t.Fatalf("expected 1 archived, got %d (err %v)", n, err)
+	}
+
+	active, err := load(path)
+	if err != nil {
+		t.Fatalf("load active: %v", err)
+	}
+	if got := ids(active.Todos()); len(got) != 1 || got[0] != 2 {
+		t.Errorf("expected [2] to stay active, got %v", got)
+	}
+	archived, err := load(archivePath(path))
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	if got := ids(archived.Todos()); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Errorf("expected [1 3] in archive, got %v", got)
+	}
+}
+
+func TestArchiveRetrySkipsArchived(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	var s Store
+	s.Add("a")
+	s.Add("b")
+	_ = s.Complete(1)
+	if err := save(path, &s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// Simulate a run where the archive was written but saving the active
+	// list failed: the done todo is in both files.
+	if err := writeJSON(archivePath(path), []Todo{s.Todos()[0]}); err != nil {
+		t.Fatalf("write archive: %v", err)
+	}
+
+	if _, err := archive(path, &s); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	archived, err := load(archivePath(path))
+	if err != nil {
+		t.Fatalf("load archive: %v", err)
+	}
+	if got := ids(archived.Todos()); len(got) != 1 || got[0] != 1 {
+		t.Errorf("expected [1] in archive without duplicates, got %v", got)
+	}
+	active, err := load(path)
+	if err != nil {
+		t.Fatalf("load active: %v", err)
+	}
+	if got := ids(active.Todos()); len(got) != 1 || got[0] != 2 {
+		t.Errorf("expected [2] to stay active, got %v", got)
+	}
+}
+
+func TestLoadPersistsBackfilledUIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.json")
+	legacy := `[{"id":1,"title":"written before UIDs","done":false}]`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatalf("write legacy file: %v", err)
+	}
+
+	first, err := load(path)
+	if err != nil {
+		t.Fatalf("first load: %v", err)
+	}
+	second, err := load(path)
+	if err != nil {
+		t.Fatalf("second load: %v", err)
+	}
+
+	uid := first.Todos()[0].UID
+	if uid == "" {
+		t.Fatal("expected a UID to be assigned")
+	}
+	if got := second.Todos()[0].UID; got != uid {
+		t.Errorf("UID changed between loads: %s then %s", uid, got)
+	}
+}
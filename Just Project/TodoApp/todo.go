@@ -1,75 +1,713 @@
 package main
 
 import (
+	"crypto/rand"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
+// dateLayout is the format used for due dates on input and output.
+const dateLayout = "2006-01-02"
+
+// Priority is the importance level of a todo.
+type Priority string
+
+const (
+	PriorityLow  Priority = "low"
+	PriorityMed  Priority = "med"
+	PriorityHigh Priority = "high"
+)
+
+// parsePriority validates a user-supplied priority level.
+func parsePriority(s string) (Priority, error) {
+	switch p := Priority(strings.ToLower(s)); p {
+	case PriorityLow, PriorityMed, PriorityHigh:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid priority %q, expected low, med or high", s)
+	}
+}
+
+// Recurrence is how often a todo repeats.
+type Recurrence string
+
+const (
+	RecurNone   Recurrence = ""
+	RecurDaily  Recurrence = "daily"
+	RecurWeekly Recurrence = "weekly"
+)
+
+// parseRecur validates a user-supplied recurrence; "none" clears it.
+func parseRecur(s string) (Recurrence, error) {
+	switch r := Recurrence(strings.ToLower(s)); r {
+	case RecurDaily, RecurWeekly:
+		return r, nil
+	case "none":
+		return RecurNone, nil
+	default:
+		return "", fmt.Errorf("invalid recurrence %q, expected daily, weekly or none", s)
+	}
+}
+
+// days returns the recurrence interval in days, or 0 for RecurNone.
+func (r Recurrence) days() int {
+	switch r {
+	case RecurDaily:
+		return 1
+	case RecurWeekly:
+		return 7
+	}
+	return 0
+}
+
 // Todo represents a single task item.
+//
+// ID is a short index for display and typing; it may be reused after the
+// highest-numbered todo is deleted. UID is a random UUID assigned once and
+// never reused, so scripts should refer to todos by UID.
 type Todo struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Done      bool      `json:"done"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int        `json:"id"`
+	UID       string     `json:"uid,omitempty"`
+	Title     string     `json:"title"`
+	Done      bool       `json:"done"`
+	CreatedAt time.Time  `json:"created_at"`
+	Due       *time.Time `json:"due,omitempty"`
+	Priority  Priority   `json:"priority,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// ParentID is the ID of the todo this one is a subtask of, or 0.
+	ParentID int `json:"parent_id,omitempty"`
+	// Recur makes completing the todo schedule its next occurrence.
+	Recur Recurrence `json:"recur,omitempty"`
+}
+
+// splitTitles splits input on semicolons and newlines into trimmed,
+// non-empty titles, so several todos can be added at once.
+func splitTitles(s string) []string {
+	var titles []string
+	for _, title := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '\n' }) {
+		if title = strings.TrimSpace(title); title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return titles
 }
 
-// Store is a slice of Todo items.
-type Store []Todo
+// parseTags splits a comma-separated list into trimmed, lowercase,
+// de-duplicated tags. Empty entries are dropped.
+func parseTags(s string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// HasTag reports whether the todo carries tag, ignoring case.
+func (t Todo) HasTag(tag string) bool {
+	for _, have := range t.Tags {
+		if strings.EqualFold(have, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// Overdue reports whether the todo is still pending after its due date.
+func (t Todo) Overdue(now time.Time) bool {
+	if t.Done || t.Due == nil {
+		return false
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return t.Due.Before(today)
+}
+
+// parseDue parses a YYYY-MM-DD date in the local time zone.
+func parseDue(s string) (time.Time, error) {
+	due, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", s)
+	}
+	return due, nil
+}
+
+// Store holds the todo list. All methods are safe for concurrent use:
+// readers take a shared lock, mutators an exclusive one.
+type Store struct {
+	mu    sync.RWMutex
+	todos []Todo
+}
+
+// NewStore returns a Store holding the given todos. Todos without a UID
+// (e.g. from files written before UIDs existed) are assigned one.
+func NewStore(todos []Todo) *Store {
+	backfillUIDs(todos)
+	return &Store{todos: todos}
+}
+
+// backfillUIDs assigns a fresh UID to every todo that lacks one and
+// reports how many it assigned.
+func backfillUIDs(todos []Todo) int {
+	n := 0
+	for i := range todos {
+		if todos[i].UID == "" {
+			todos[i].UID = newUID()
+			n++
+		}
+	}
+	return n
+}
+
+// newUID returns a random (version 4) UUID string.
+func newUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("todo: cannot read random bytes: " + err.Error())
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// cloneTodos returns a deep copy of todos, so later edits to either copy
+// (including due dates and tags) don't affect the other.
+func cloneTodos(todos []Todo) []Todo {
+	if len(todos) == 0 {
+		return nil
+	}
+	out := make([]Todo, len(todos))
+	for i, t := range todos {
+		if t.Due != nil {
+			due := *t.Due
+			t.Due = &due
+		}
+		if t.CompletedAt != nil {
+			completed := *t.CompletedAt
+			t.CompletedAt = &completed
+		}
+		t.Tags = append([]string(nil), t.Tags...)
+		out[i] = t
+	}
+	return out
+}
+
+// Todos returns a snapshot copy of all todos in their current order.
+func (s *Store) Todos() []Todo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneTodos(s.todos)
+}
+
+// Len returns the number of todos.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.todos)
+}
+
+// Get returns a copy of the Todo with the given ID.
+func (s *Store) Get(id int) (Todo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, t := range s.todos {
+		if t.ID == id {
+			return cloneTodos([]Todo{t})[0], true
+		}
+	}
+	return Todo{}, false
+}
+
+// replace swaps the whole list, e.g. when restoring an undo snapshot.
+func (s *Store) replace(todos []Todo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.todos = todos
+}
+
+// update applies fn to the Todo with the given ID under the write lock.
+func (s *Store) update(id int, fn func(t *Todo)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.todos {
+		if s.todos[i].ID == id {
+			fn(&s.todos[i])
+			return nil
+		}
+	}
+	return fmt.Errorf("todo %d not found", id)
+}
 
 // Add creates a new Todo with a monotonically increasing ID.
 func (s *Store) Add(title string) Todo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(title, 0)
+}
+
+// AddSub creates a new Todo as a subtask of the todo with ID parentID.
+func (s *Store) AddSub(parentID int, title string) (Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index(parentID) < 0 {
+		return Todo{}, fmt.Errorf("parent todo %d not found", parentID)
+	}
+	return s.add(title, parentID), nil
+}
+
+// Lookup resolves a user-supplied reference to a todo's short ID. ref may
+// be the short ID itself, the full UID, or an unambiguous UID prefix.
+func (s *Store) Lookup(ref string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if id, err := strconv.Atoi(ref); err == nil {
+		if s.index(id) < 0 {
+			return 0, fmt.Errorf("todo %d not found", id)
+		}
+		return id, nil
+	}
+	if ref == "" {
+		return 0, fmt.Errorf("empty todo reference")
+	}
+
+	found := 0
+	matches := 0
+	for _, t := range s.todos {
+		if t.UID == ref {
+			return t.ID, nil
+		}
+		if strings.HasPrefix(t.UID, strings.ToLower(ref)) {
+			found = t.ID
+			matches++
+		}
+	}
+	switch matches {
+	case 0:
+		return 0, fmt.Errorf("todo %q not found", ref)
+	case 1:
+		return found, nil
+	default:
+		return 0, fmt.Errorf("todo reference %q is ambiguous", ref)
+	}
+}
+
+// index returns the position of the todo with the given ID, or -1.
+// The caller must hold the lock.
+func (s *Store) index(id int) int {
+	for i, t := range s.todos {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// add appends a new todo; the caller must hold the write lock.
+func (s *Store) add(title string, parentID int) Todo {
 	maxID := 0
-	for _, t := range *s {
+	for _, t := range s.todos {
 		if t.ID > maxID {
 			maxID = t.ID
 		}
 	}
 	todo := Todo{
 		ID:        maxID + 1,
+		UID:       newUID(),
 		Title:     title,
 		Done:      false,
 		CreatedAt: time.Now(),
+		ParentID:  parentID,
 	}
-	*s = append(*s, todo)
+	s.todos = append(s.todos, todo)
 	return todo
 }
 
-// Complete marks the Todo with the given ID as done.
+// Complete marks the Todo with the given ID as done and records when.
+// Completing an already done todo keeps the original timestamp.
 func (s *Store) Complete(id int) error {
-	for i, t := range *s {
-		if t.ID == id {
-			(*s)[i].Done = true
-			return nil
-		}
+	_, err := s.CompleteNext(id)
+	return err
+}
+
+// CompleteNext is like Complete, but for a recurring todo it also adds the
+// next occurrence and returns it (nil otherwise). The next occurrence copies
+// the title, priority, tags, parent and recurrence, and is due one interval
+// after the completed one (or after today if it had no due date). The
+// completed todo stops recurring, so undoing and redoing it won't add a
+// second copy.
+func (s *Store) CompleteNext(id int) (*Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return nil, fmt.Errorf("todo %d not found", id)
 	}
-	return fmt.Errorf("todo %d not found", id)
+	t := &s.todos[i]
+	if t.Done {
+		return nil, nil
+	}
+
+	now := time.Now()
+	t.Done = true
+	t.CompletedAt = &now
+	if t.Recur == RecurNone {
+		return nil, nil
+	}
+
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if t.Due != nil {
+		from = *t.Due
+	}
+	due := from.AddDate(0, 0, t.Recur.days())
+	prev := *t
+	t.Recur = RecurNone
+
+	next := s.add(prev.Title, prev.ParentID)
+	next.Due = &due
+	next.Priority = prev.Priority
+	next.Tags = append([]string(nil), prev.Tags...)
+	next.Recur = prev.Recur
+	s.todos[len(s.todos)-1] = next
+	return &next, nil
+}
+
+// Uncomplete marks the Todo with the given ID as not done and clears its
+// completion timestamp.
+func (s *Store) Uncomplete(id int) error {
+	return s.update(id, func(t *Todo) {
+		t.Done = false
+		t.CompletedAt = nil
+	})
+}
+
+// SetDue sets (or, with nil, clears) the due date of the Todo with the given ID.
+func (s *Store) SetDue(id int, due *time.Time) error {
+	return s.update(id, func(t *Todo) { t.Due = due })
 }
 
-// Delete removes the Todo with the given ID from the store.
+// Postpone pushes the due date of the Todo with the given ID forward by d.
+// Whole days are added on the calendar, so a due date stays at local
+// midnight across daylight saving changes. It fails if the todo has no
+// due date.
+func (s *Store) Postpone(id int, d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("postpone duration must be positive, got %v", d)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return fmt.Errorf("todo %d not found", id)
+	}
+	t := &s.todos[i]
+	if t.Due == nil {
+		return fmt.Errorf("todo %d has no due date", id)
+	}
+
+	const day = 24 * time.Hour
+	var due time.Time
+	if d%day == 0 {
+		due = t.Due.AddDate(0, 0, int(d/day))
+	} else {
+		due = t.Due.Add(d)
+	}
+	t.Due = &due
+	return nil
+}
+
+// SetPriority validates level and assigns it to the Todo with the given ID.
+func (s *Store) SetPriority(id int, level string) error {
+	p, err := parsePriority(level)
+	if err != nil {
+		return err
+	}
+	return s.update(id, func(t *Todo) { t.Priority = p })
+}
+
+// SetRecur validates level and sets the recurrence of the Todo with the given ID.
+func (s *Store) SetRecur(id int, level string) error {
+	r, err := parseRecur(level)
+	if err != nil {
+		return err
+	}
+	return s.update(id, func(t *Todo) { t.Recur = r })
+}
+
+// SetTags replaces the tags of the Todo with the given ID.
+func (s *Store) SetTags(id int, tags []string) error {
+	return s.update(id, func(t *Todo) { t.Tags = tags })
+}
+
+// Delete removes the Todo with the given ID from the store. Its subtasks
+// move up to the deleted todo's parent; use DeleteTree to remove them too.
 func (s *Store) Delete(id int) error {
-	for i, t := range *s {
-		if t.ID == id {
-			*s = append((*s)[:i], (*s)[i+1:]...)
-			return nil
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.index(id)
+	if i < 0 {
+		return fmt.Errorf("todo %d not found", id)
+	}
+	parentID := s.todos[i].ParentID
+	s.todos = append(s.todos[:i], s.todos[i+1:]...)
+	for j := range s.todos {
+		if s.todos[j].ParentID == id {
+			s.todos[j].ParentID = parentID
 		}
 	}
-	return fmt.Errorf("todo %d not found", id)
+	return nil
+}
+
+// DeleteTree removes the Todo with the given ID together with all of its
+// subtasks, and returns how many todos were removed.
+func (s *Store) DeleteTree(id int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.index(id) < 0 {
+		return 0, fmt.Errorf("todo %d not found", id)
+	}
+
+	doomed := map[int]bool{id: true}
+	// Repeat until no new descendants are found; subtasks may be stored
+	// before their parents after a sort.
+	for grew := true; grew; {
+		grew = false
+		for _, t := range s.todos {
+			if doomed[t.ParentID] && !doomed[t.ID] {
+				doomed[t.ID] = true
+				grew = true
+			}
+		}
+	}
+
+	kept := s.todos[:0]
+	for _, t := range s.todos {
+		if !doomed[t.ID] {
+			kept = append(kept, t)
+		}
+	}
+	removed := len(s.todos) - len(kept)
+	s.todos = kept
+	return removed, nil
+}
+
+// Children returns the direct subtasks of the todo with the given ID.
+func (s *Store) Children(id int) []Todo {
+	return s.filter(func(t Todo) bool { return t.ParentID == id })
+}
+
+// ClearCompleted removes every done todo and returns how many were removed.
+// Pending todos keep their order.
+func (s *Store) ClearCompleted() int {
+	return len(s.TakeCompleted())
+}
+
+// TakeCompleted removes every done todo and returns them in order.
+// Pending todos keep their order.
+func (s *Store) TakeCompleted() []Todo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var taken []Todo
+	kept := s.todos[:0]
+	for _, t := range s.todos {
+		if t.Done {
+			taken = append(taken, t)
+		} else {
+			kept = append(kept, t)
+		}
+	}
+	s.todos = kept
+	return taken
+}
+
+// Search returns the todos whose title contains keyword, ignoring case.
+// An empty keyword matches every todo.
+func (s *Store) Search(keyword string) []Todo {
+	keyword = strings.ToLower(keyword)
+	return s.filter(func(t Todo) bool {
+		return strings.Contains(strings.ToLower(t.Title), keyword)
+	})
+}
+
+// FilterByTag returns the todos tagged with tag, ignoring case.
+func (s *Store) FilterByTag(tag string) []Todo {
+	return s.filter(func(t Todo) bool { return t.HasTag(tag) })
+}
+
+// Filter returns the todos that are done (done == true) or still pending.
+func (s *Store) Filter(done bool) []Todo {
+	return s.filter(func(t Todo) bool { return t.Done == done })
+}
+
+// filter returns copies of the todos matching keep, in order.
+func (s *Store) filter(keep func(t Todo) bool) []Todo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []Todo
+	for _, t := range s.todos {
+		if keep(t) {
+			result = append(result, t)
+		}
+	}
+	return cloneTodos(result)
+}
+
+// Stats counts all todos, the done and pending ones, and the pending
+// ones that are past their due date.
+func (s *Store) Stats() (total, done, pending, overdue int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := time.Now()
+	for _, t := range s.todos {
+		if t.Done {
+			done++
+		} else {
+			pending++
+		}
+		if t.Overdue(now) {
+			overdue++
+		}
+	}
+	return len(s.todos), done, pending, overdue
+}
+
+// priorityRank orders priorities from most to least important;
+// todos without a priority sort last.
+var priorityRank = map[Priority]int{PriorityHigh: 0, PriorityMed: 1, PriorityLow: 2, "": 3}
+
+// Sort reorders the store in place by the given field: id, title, created,
+// done (pending first) or priority (high first). Ties keep their order.
+func (s *Store) Sort(by string) error {
+	var less func(a, b Todo) bool
+	switch strings.ToLower(by) {
+	case "id":
+		less = func(a, b Todo) bool { return a.ID < b.ID }
+	case "title":
+		less = func(a, b Todo) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	case "created":
+		less = func(a, b Todo) bool { return a.CreatedAt.Before(b.CreatedAt) }
+	case "done":
+		less = func(a, b Todo) bool { return !a.Done && b.Done }
+	case "priority":
+		less = func(a, b Todo) bool { return priorityRank[a.Priority] < priorityRank[b.Priority] }
+	default:
+		return fmt.Errorf("cannot sort by %q, expected id, title, created, done or priority", by)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	todos := s.todos
+	sort.SliceStable(todos, func(i, j int) bool { return less(todos[i], todos[j]) })
+	return nil
 }
 
 // Print displays all todos in a formatted table.
-func (s Store) Print() {
-	if len(s) == 0 {
+func (s *Store) Print() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.todos) == 0 {
 		fmt.Println("No todos yet. Add one with --add")
 		return
 	}
-	fmt.Printf("%-4s  %-6s  %-30s  %s\n", "ID", "Status", "Title", "Created")
-	fmt.Printf("%-4s  %-6s  %-30s  %s\n", "----", "------", "------------------------------", "-------------------")
-	for _, t := range s {
+	printTodos(s.todos)
+}
+
+// printTodos renders todos as a table on stdout.
+func printTodos(todos []Todo) {
+	writeTable(os.Stdout, todos, useColor)
+}
+
+// writeTable renders todos as a table on w. With color on, done rows are
+// dim green and overdue rows red.
+func writeTable(w io.Writer, todos []Todo, color bool) {
+	now := time.Now()
+	fmt.Fprintf(w, "%-4s  %-6s  %-4s  %-30s  %-18s  %-16s  %-16s  %s\n", "ID", "Status", "Prio", "Title", "Due", "Created", "Completed", "Tags")
+	fmt.Fprintf(w, "%-4s  %-6s  %-4s  %-30s  %-18s  %-16s  %-16s  %s\n", "----", "------", "----", "------------------------------", "------------------", "----------------", "----------------", "----")
+	for _, n := range nest(todos) {
+		t := n.todo
 		status := "[ ]"
 		if t.Done {
 			status = "[✓]"
 		}
+		due := "-"
+		if t.Due != nil {
+			due = t.Due.Format(dateLayout)
+			if t.Overdue(now) {
+				due += " OVERDUE"
+			}
+		}
+		prio := string(t.Priority)
+		if prio == "" {
+			prio = "-"
+		}
 		created := t.CreatedAt.Format("2006-01-02 15:04")
-		fmt.Printf("%-4d  %-6s  %-30s  %s\n", t.ID, status, t.Title, created)
+		completed := "-"
+		if t.Done && t.CompletedAt != nil {
+			completed = t.CompletedAt.Format("2006-01-02 15:04")
+		}
+		tags := strings.Join(t.Tags, ",")
+		title := strings.Repeat("  ", n.depth) + t.Title
+		row := fmt.Sprintf("%-4d  %-6s  %-4s  %-30s  %-18s  %-16s  %-16s  %s", t.ID, status, prio, title, due, created, completed, tags)
+		switch {
+		case t.Done:
+			row = colorize(row, ansiDimGreen, color)
+		case t.Overdue(now):
+			row = colorize(row, ansiRed, color)
+		}
+		fmt.Fprintln(w, row)
+	}
+}
+
+// nested is a todo paired with its depth in the subtask hierarchy.
+type nested struct {
+	todo  Todo
+	depth int
+}
+
+// nest orders todos so that each subtask follows its parent, depth first.
+// Todos whose parent isn't in the list are treated as top-level.
+func nest(todos []Todo) []nested {
+	present := make(map[int]bool, len(todos))
+	for _, t := range todos {
+		present[t.ID] = true
+	}
+	children := make(map[int][]Todo)
+	var roots []Todo
+	for _, t := range todos {
+		if t.ParentID != 0 && present[t.ParentID] {
+			children[t.ParentID] = append(children[t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	out := make([]nested, 0, len(todos))
+	var walk func(t Todo, depth int)
+	walk = func(t Todo, depth int) {
+		out = append(out, nested{todo: t, depth: depth})
+		for _, c := range children[t.ID] {
+			walk(c, depth+1)
+		}
+	}
+	for _, t := range roots {
+		walk(t, 0)
 	}
+	return out
 }
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOverdue(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	yesterday := time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local)
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name string
+		todo Todo
+		want bool
+	}{
+		{"no_due", Todo{}, false},
+		{"due_yesterday", Todo{Due: &yesterday}, true},
+		{"due_today", Todo{Due: &today}, false},
+		{"done_past_due", Todo{Done: true, Due: &yesterday}, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.todo.Overdue(now); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSetPriority(t *testing.T) {
+	var s Store
+	todo := s.Add("pay rent")
+
+	if err := s.SetPriority(todo.ID, "urgent"); err == nil {
+		t.Error("expected error for invalid priority")
+	}
+	if s.Todos()[0].Priority != "" {
+		t.Errorf("invalid priority must not be stored, got %q", s.Todos()[0].Priority)
+	}
+
+	if err := s.SetPriority(todo.ID, "HIGH"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Todos()[0].Priority != PriorityHigh {
+		t.Errorf("expected %q, got %q", PriorityHigh, s.Todos()[0].Priority)
+	}
+
+	if err := s.SetPriority(99, "low"); err == nil {
+		t.Error("expected error for missing todo")
+	}
+}
+
+func TestUncomplete(t *testing.T) {
+	var s Store
+	todo := s.Add("write tests")
+
+	if err := s.Complete(todo.ID); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if err := s.Uncomplete(todo.ID); err != nil {
+		t.Fatalf("Uncomplete: %v", err)
+	}
+	if s.Todos()[0].Done {
+		t.Error("expected Done to be false after Uncomplete")
+	}
+
+	if err := s.Uncomplete(42); err == nil {
+		t.Error("expected error for missing todo")
+	}
+}
+
+func TestSearch(t *testing.T) {
+	var s Store
+	s.Add("Buy milk")
+	s.Add("Learn goroutines")
+	s.Add("buy new keyboard")
+
+	tests := []struct {
+		keyword string
+		wantIDs []int
+	}{
+		{"buy", []int{1, 3}},
+		{"GOROUTINE", []int{2}},
+		{"nothing", nil},
+		{"", []int{1, 2, 3}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.keyword, func(t *testing.T) {
+			got := s.Search(tc.keyword)
+			if len(got) != len(tc.wantIDs) {
+				t.Fatalf("expected %d results, got %d: %+v", len(tc.wantIDs), len(got), got)
+			}
+			for i, id := range tc.wantIDs {
+				if got[i].ID != id {
+					t.Errorf("result %d: expected ID %d, got %d", i, id, got[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestSort(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	seed := []Todo{
+		{ID: 1, Title: "charlie", Done: true, CreatedAt: base.Add(2 * time.Hour), Priority: PriorityLow},
+		{ID: 2, Title: "Alpha", Done: false, CreatedAt: base.Add(3 * time.Hour)},
+		{ID: 3, Title: "bravo", Done: true, CreatedAt: base, Priority: PriorityHigh},
+		{ID: 4, Title: "delta", Done: false, CreatedAt: base.Add(time.Hour), Priority: PriorityMed},
+	}
+
+	tests := []struct {
+		by      string
+		wantIDs []int
+	}{
+		{"id", []int{1, 2, 3, 4}},
+		{"title", []int{2, 3, 1, 4}},
+		{"created", []int{3, 4, 1, 2}},
+		{"done", []int{2, 4, 1, 3}},
+		{"priority", []int{3, 4, 1, 2}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.by, func(t *testing.T) {
+			s := NewStore(cloneTodos(seed))
+			if err := s.Sort(tc.by); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := ids(s.Todos())
+			for i, id := range tc.wantIDs {
+				if got[i] != id {
+					t.Fatalf("expected order %v, got %v", tc.wantIDs, got)
+				}
+			}
+		})
+	}
+
+	s := NewStore(cloneTodos(seed))
+	if err := s.Sort("color"); err == nil {
+		t.Error("expected error for unknown field")
+	}
+}
+
+// ids returns the IDs of todos in order.
+func ids(todos []Todo) []int {
+	result := make([]int, len(todos))
+	for i, t := range todos {
+		result[i] = t.ID
+	}
+	return result
+}
+
+func TestFilterByTag(t *testing.T) {
+	var s Store
+	s.Add("deploy")
+	s.Add("groceries")
+	s.Add("code review")
+	_ = s.SetTags(1, parseTags("Work, urgent"))
+	_ = s.SetTags(2, parseTags("home"))
+	_ = s.SetTags(3, parseTags("work,,work"))
+
+	if got := ids(s.FilterByTag("work")); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Errorf("expected [1 3] for tag work, got %v", got)
+	}
+	if got := ids(s.FilterByTag("HOME")); len(got) != 1 || got[0] != 2 {
+		t.Errorf("expected [2] for tag home, got %v", got)
+	}
+	if got := s.FilterByTag("missing"); len(got) != 0 {
+		t.Errorf("expected no results, got %v", ids(got))
+	}
+	if len(s.Todos()[2].Tags) != 1 {
+		t.Errorf("expected duplicate tags to be collapsed, got %v", s.Todos()[2].Tags)
+	}
+}
+
+func TestClearCompleted(t *testing.T) {
+	var s Store
+	for _, title := range []string{"a", "b", "c", "d", "e"} {
+		s.Add(title)
+	}
+	_ = s.Complete(1)
+	_ = s.Complete(3)
+	_ = s.Complete(4)
+
+	if n := s.ClearCompleted(); n != 3 {
+		t.Errorf("expected 3 removed, got %d", n)
+	}
+	if got := ids(s.Todos()); len(got) != 2 || got[0] != 2 || got[1] != 5 {
+		t.Errorf("expected pending todos [2 5] to remain, got %v", got)
+	}
+	for _, todo := range s.Todos() {
+		if todo.Done {
+			t.Errorf("todo %d is done but was kept", todo.ID)
+		}
+	}
+
+	if n := s.ClearCompleted(); n != 0 {
+		t.Errorf("expected nothing to clear, got %d", n)
+	}
+}
+
+func TestCompletedAt(t *testing.T) {
+	var s Store
+	todo := s.Add("retro notes")
+	if s.Todos()[0].CompletedAt != nil {
+		t.Fatal("new todo must not have a completion time")
+	}
+
+	before := time.Now()
+	if err := s.Complete(todo.ID); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	if s.Todos()[0].CompletedAt == nil || s.Todos()[0].CompletedAt.Before(before) {
+		t.Fatalf("expected completion time >= %v, got %v", before, s.Todos()[0].CompletedAt)
+	}
+
+	// Completing again keeps the original timestamp.
+	first := *s.Todos()[0].CompletedAt
+	_ = s.Complete(todo.ID)
+	if !s.Todos()[0].CompletedAt.Equal(first) {
+		t.Errorf("expected timestamp %v to be kept, got %v", first, s.Todos()[0].CompletedAt)
+	}
+
+	if err := s.Uncomplete(todo.ID); err != nil {
+		t.Fatalf("Uncomplete: %v", err)
+	}
+	if s.Todos()[0].CompletedAt != nil {
+		t.Errorf("expected nil completion time after Uncomplete, got %v", s.Todos()[0].CompletedAt)
+	}
+}
+
+func TestConcurrentAdd(t *testing.T) {
+	var s Store
+	const workers, perWorker = 8, 25
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				s.Add("task")
+				s.Search("task")
+			}
+		}()
+	}
+	wg.Wait()
+
+	todos := s.Todos()
+	if len(todos) != workers*perWorker {
+		t.Fatalf("expected %d todos, got %d", workers*perWorker, len(todos))
+	}
+	seen := make(map[int]bool)
+	for _, todo := range todos {
+		if seen[todo.ID] {
+			t.Fatalf("duplicate ID %d", todo.ID)
+		}
+		seen[todo.ID] = true
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var s Store
+	for _, title := range []string{"a", "b", "c", "d"} {
+		s.Add(title)
+	}
+	_ = s.Complete(2)
+	_ = s.Complete(3)
+
+	if got := ids(s.Filter(false)); len(got) != 2 || got[0] != 1 || got[1] != 4 {
+		t.Errorf("expected pending [1 4], got %v", got)
+	}
+	if got := ids(s.Filter(true)); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("expected done [2 3], got %v", got)
+	}
+	if s.Len() != 4 {
+		t.Errorf("Filter must not modify the store, got %d todos", s.Len())
+	}
+}
+
+func TestSubtasks(t *testing.T) {
+	var s Store
+	parent := s.Add("release v2")
+	child, err := s.AddSub(parent.ID, "write changelog")
+	if err != nil {
+		t.Fatalf("AddSub: %v", err)
+	}
+	if _, err := s.AddSub(child.ID, "collect PR titles"); err != nil {
+		t.Fatalf("AddSub: %v", err)
+	}
+	s.Add("unrelated")
+
+	if child.ParentID != parent.ID {
+		t.Errorf("expected parent %d, got %d", parent.ID, child.ParentID)
+	}
+	if got := ids(s.Children(parent.ID)); len(got) != 1 || got[0] != child.ID {
+		t.Errorf("expected children [%d], got %v", child.ID, got)
+	}
+	if _, err := s.AddSub(99, "orphan"); err == nil {
+		t.Error("expected error for missing parent")
+	}
+
+	var depths []int
+	for _, n := range nest(s.Todos()) {
+		depths = append(depths, n.depth)
+	}
+	if want := []int{0, 1, 2, 0}; fmt.Sprint(depths) != fmt.Sprint(want) {
+		t.Errorf("expected depths %v, got %v", want, depths)
+	}
+
+	n, err := s.DeleteTree(parent.ID)
+	if err != nil {
+		t.Fatalf("DeleteTree: %v", err)
+	}
+	if n != 3 || s.Len() != 1 {
+		t.Errorf("expected 3 removed and 1 left, got %d removed, %d left", n, s.Len())
+	}
+}
+
+func TestDeleteReparentsSubtasks(t *testing.T) {
+	var s Store
+	root := s.Add("root")
+	mid, _ := s.AddSub(root.ID, "mid")
+	leaf, _ := s.AddSub(mid.ID, "leaf")
+
+	if err := s.Delete(mid.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	got, ok := s.Get(leaf.ID)
+	if !ok || got.ParentID != root.ID {
+		t.Errorf("expected leaf to move under %d, got %+v", root.ID, got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	past := time.Date(2000, 1, 1, 0, 0, 0, 0, time.Local)
+	future := time.Date(2999, 1, 1, 0, 0, 0, 0, time.Local)
+	s := NewStore([]Todo{
+		{ID: 1, Title: "late", Due: &past},
+		{ID: 2, Title: "late but done", Done: true, Due: &past},
+		{ID: 3, Title: "on time", Due: &future},
+		{ID: 4, Title: "no due"},
+		{ID: 5, Title: "finished", Done: true},
+	})
+
+	total, done, pending, overdue := s.Stats()
+	if total != 5 || done != 2 || pending != 3 || overdue != 1 {
+		t.Errorf("expected 5/2/3/1, got %d/%d/%d/%d", total, done, pending, overdue)
+	}
+}
+
+func TestPostpone(t *testing.T) {
+	var s Store
+	withDue := s.Add("renew passport")
+	noDue := s.Add("someday")
+	due := time.Date(2026, 3, 28, 0, 0, 0, 0, time.Local)
+	_ = s.SetDue(withDue.ID, &due)
+
+	if err := s.Postpone(withDue.ID, 3*24*time.Hour); err != nil {
+		t.Fatalf("Postpone: %v", err)
+	}
+	got, _ := s.Get(withDue.ID)
+	if got.Due.Format(dateLayout) != "2026-03-31" {
+		t.Errorf("expected due 2026-03-31, got %s", got.Due.Format(dateLayout))
+	}
+	if got.Due.Hour() != 0 {
+		t.Errorf("expected due to stay at midnight, got %v", got.Due)
+	}
+
+	if err := s.Postpone(noDue.ID, 24*time.Hour); err == nil {
+		t.Error("expected error for todo without a due date")
+	}
+	if err := s.Postpone(42, 24*time.Hour); err == nil {
+		t.Error("expected error for missing todo")
+	}
+}
+
+func TestUIDsStableAcrossDeletes(t *testing.T) {
+	var s Store
+	s.Add("a")
+	s.Add("b")
+	third := s.Add("c")
+
+	before := make(map[int]string)
+	for _, todo := range s.Todos() {
+		before[todo.ID] = todo.UID
+	}
+
+	seen := map[string]bool{}
+	for _, uid := range before {
+		seen[uid] = true
+	}
+	// Deleting the newest todo frees its short ID for reuse, but never its UID.
+	for i := 0; i < 5; i++ {
+		last := s.Todos()[s.Len()-1]
+		if err := s.Delete(last.ID); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		added := s.Add("again")
+		if added.ID != third.ID {
+			t.Fatalf("expected short ID %d to be reused, got %d", third.ID, added.ID)
+		}
+		if seen[added.UID] {
+			t.Fatalf("UID %s was reused", added.UID)
+		}
+		seen[added.UID] = true
+	}
+
+	for _, todo := range s.Todos()[:2] {
+		if todo.UID != before[todo.ID] {
+			t.Errorf("UID of todo %d changed from %s to %s", todo.ID, before[todo.ID], todo.UID)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	var s Store
+	a := s.Add("a")
+	b := s.Add("b")
+
+	for _, ref := range []string{"1", a.UID, a.UID[:13]} {
+		if id, err := s.Lookup(ref); err != nil || id != a.ID {
+			t.Errorf("Lookup(%q): expected %d, got %d (err %v)", ref, a.ID, id, err)
+		}
+	}
+	if id, err := s.Lookup(b.UID); err != nil || id != b.ID {
+		t.Errorf("Lookup by UID: expected %d, got %d (err %v)", b.ID, id, err)
+	}
+	if _, err := s.Lookup(""); err == nil {
+		t.Error("expected error for empty reference")
+	}
+	if _, err := s.Lookup("7"); err == nil {
+		t.Error("expected error for missing short ID")
+	}
+	if _, err := s.Lookup("not-a-uid"); err == nil {
+		t.Error("expected error for unknown UID")
+	}
+
+}
+
+func TestCompleteRecurring(t *testing.T) {
+	var s Store
+	todo := s.Add("water plants")
+	due := time.Date(2026, 5, 10, 0, 0, 0, 0, time.Local)
+	_ = s.SetDue(todo.ID, &due)
+	_ = s.SetPriority(todo.ID, "high")
+	if err := s.SetRecur(todo.ID, "daily"); err != nil {
+		t.Fatalf("SetRecur: %v", err)
+	}
+
+	next, err := s.CompleteNext(todo.ID)
+	if err != nil {
+		t.Fatalf("CompleteNext: %v", err)
+	}
+	if next == nil {
+		t.Fatal("expected a next occurrence")
+	}
+	if s.Len() != 2 {
+		t.Fatalf("expected 2 todos, got %d", s.Len())
+	}
+
+	done, _ := s.Get(todo.ID)
+	if !done.Done || done.Recur != RecurNone {
+		t.Errorf("expected original to be done and no longer recurring, got %+v", done)
+	}
+	got, _ := s.Get(next.ID)
+	if got.Done || got.Title != "water plants" || got.Recur != RecurDaily || got.Priority != PriorityHigh {
+		t.Errorf("unexpected next occurrence: %+v", got)
+	}
+	if got.Due == nil || got.Due.Format(dateLayout) != "2026-05-11" {
+		t.Errorf("expected next due 2026-05-11, got %v", got.Due)
+	}
+
+	// Completing a plain todo adds nothing.
+	plain := s.Add("one-off")
+	if next, _ := s.CompleteNext(plain.ID); next != nil || s.Len() != 3 {
+		t.Errorf("expected no next occurrence for a plain todo, got %+v", next)
+	}
+
+	if err := s.SetRecur(plain.ID, "monthly"); err == nil {
+		t.Error("expected error for invalid recurrence")
+	}
+}
+
+func TestSplitTitles(t *testing.T) {
+	got := splitTitles("Buy milk; Call mom\nFix bike;; \n")
+	want := []string{"Buy milk", "Call mom", "Fix bike"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if got := splitTitles(" ; "); len(got) != 0 {
+		t.Errorf("expected no titles, got %q", got)
+	}
+}
@@ -5,8 +5,9 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"text/tabwriter"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/weather-cli/internal/weather"
 )
@@ -14,11 +15,39 @@ import (
 func main() {
 	var (
 		apiKey  = flag.String("key", "", "OpenWeatherMap API key (overrides OWM_API_KEY env)")
-		city    = flag.String("city", "Almaty", "City name to check weather for")
+		city    = flag.String("city", "Almaty", "City name, or a comma-separated list of cities")
 		timeout = flag.Duration("timeout", 5*time.Second, "HTTP request timeout")
+		units   = flag.String("units", weather.UnitsMetric, "Units: metric (°C, m/s) or imperial (°F, mph)")
+		lat     = flag.Float64("lat", 0, "Latitude; with -lon, takes precedence over -city")
+		lon     = flag.Float64("lon", 0, "Longitude; with -lat, takes precedence over -city")
+		asJSON  = flag.Bool("json", false, "Print results as indented JSON instead of a table")
+		ttl     = flag.Duration("cache-ttl", 10*time.Minute, "Reuse responses for the same location this long (0 disables)")
+		retries = flag.Int("retries", 3, "Max attempts per request on 5xx or network errors")
+		air     = flag.Bool("air", false, "Also fetch and show the air quality index")
+		uv      = flag.Bool("uv", false, "Also fetch and show the UV index")
+		lang    = flag.String("lang", "en", "Language for weather descriptions (e.g. en, ru, de)")
+		zip     = flag.String("zip", "", "ZIP/postal code; takes precedence over -city")
+		country = flag.String("country", "us", "Country code for -zip")
+		outPath = flag.String("out", "", "Append output to this file instead of printing it")
+		verbose = flag.Bool("verbose", false, "Log each request URL (key redacted) and response status to stderr")
 	)
 	flag.Parse()
 
+	useCoords, err := coordsGiven()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	if useCoords && (*lat < -90 || *lat > 90 || *lon < -180 || *lon > 180) {
+		fmt.Fprintln(os.Stderr, "error: -lat must be within [-90, 90] and -lon within [-180, 180]")
+		os.Exit(1)
+	}
+
+	if !weather.ValidUnits(*units) {
+		fmt.Fprintf(os.Stderr, "error: invalid -units %q, expected metric or imperial\n", *units)
+		os.Exit(1)
+	}
+
 	key := resolveAPIKey(*apiKey)
 	if key == "" {
 		fmt.Fprintln(os.Stderr, "error: API key is required. Use -key flag or set OWM_API_KEY environment variable.")
@@ -26,68 +55,158 @@ func main() {
 	}
 
 	client := weather.NewClient(key, *timeout)
+	client.Units = *units
+	client.Lang = *lang
+	client.CacheTTL = *ttl
+	client.MaxAttempts = *retries
+	if *verbose {
+		client.Verbose = os.Stderr
+	}
 
 	// Context with timeout gives us a hard deadline independent of the HTTP client timeout.
 	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
-	w, err := client.FetchWeather(ctx, *city)
+	var (
+		reports []report
+		failed  bool
+	)
+	switch {
+	case useCoords:
+		w, err := client.FetchWeatherByCoords(ctx, *lat, *lon)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		reports = append(reports, report{Weather: w})
+	case *zip != "":
+		w, err := client.FetchWeatherByZip(ctx, *zip, *country)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		reports = append(reports, report{Weather: w})
+	default:
+		cities := splitCities(*city)
+		if len(cities) == 0 {
+			fmt.Fprintln(os.Stderr, "error: -city must name at least one city")
+			os.Exit(1)
+		}
+		var errs []error
+		reports, errs = fetchCities(ctx, client, cities)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			failed = true
+		}
+	}
+
+	if *air {
+		for i := range reports {
+			w := reports[i].Weather
+			aq, err := client.FetchAirQuality(ctx, w.Coord.Lat, w.Coord.Lon)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: air quality for %s: %v\n", w.Name, err)
+				failed = true
+				continue
+			}
+			reports[i].Air = aq
+		}
+	}
+
+	if *uv {
+		for i := range reports {
+			w := reports[i].Weather
+			uvi, err := client.FetchUVIndex(ctx, w.Coord.Lat, w.Coord.Lon)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error: UV index for %s: %v\n", w.Name, err)
+				failed = true
+				continue
+			}
+			reports[i].UV = uvi
+		}
+	}
+
+	if *outPath != "" {
+		err = appendReports(*outPath, reports, *units, *asJSON)
+	} else {
+		err = writeReports(os.Stdout, reports, *units, *asJSON)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
-
-	printWeather(w)
+	if failed {
+		os.Exit(1)
+	}
 }
 
-// resolveAPIKey returns the API key following the priority chain:
-// flag > environment variable > empty string.
-func resolveAPIKey(flagValue string) string {
-	if flagValue != "" {
-		return flagValue
+// fetchCities fetches every city from p, returning reports for the ones
+// that succeeded and one error per city that failed.
+func fetchCities(ctx context.Context, p weather.Provider, cities []string) ([]report, []error) {
+	var (
+		reports []report
+		errs    []error
+	)
+	for _, res := range weather.FetchMany(ctx, p, cities, maxConcurrent) {
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.City, res.Err))
+			continue
+		}
+		reports = append(reports, report{Weather: res.Weather})
 	}
-	return os.Getenv("OWM_API_KEY")
+	return reports, errs
 }
 
-func weatherEmoji(condition string) string {
-	switch condition {
-	case "Clear":
-		return "☀️"
-	case "Clouds":
-		return "☁️"
-	case "Rain", "Drizzle":
-		return "🌧️"
-	case "Thunderstorm":
-		return "⛈️"
-	case "Snow":
-		return "❄️"
-	case "Mist", "Fog", "Haze":
-		return "🌫️"
-	default:
-		return "🌡️"
+// maxConcurrent bounds how many cities are fetched at the same time.
+const maxConcurrent = 4
+
+// splitCities splits a comma-separated -city value into trimmed,
+// non-empty city names. Two-letter parts are state or country codes
+// ("London,GB", "Portland,OR,US") and stay attached to the preceding city.
+func splitCities(s string) []string {
+	var cities []string
+	for _, c := range strings.Split(s, ",") {
+		c = strings.TrimSpace(c)
+		switch {
+		case c == "":
+		case len(cities) > 0 && isRegionCode(c):
+			cities[len(cities)-1] += "," + c
+		default:
+			cities = append(cities, c)
+		}
 	}
+	return cities
 }
 
-func printWeather(w *weather.WeatherResponse) {
-	condition := ""
-	description := ""
-	if len(w.Weather) > 0 {
-		condition = w.Weather[0].Main
-		description = w.Weather[0].Description
+// isRegionCode reports whether s looks like a two-letter state or country code.
+func isRegionCode(s string) bool {
+	if len(s) != 2 {
+		return false
 	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) || r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
 
-	emoji := weatherEmoji(condition)
-
-	fmt.Printf("\n%s  Weather in %s, %s\n", emoji, w.Name, w.Sys.Country)
-	fmt.Println("─────────────────────────────────")
-
-	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintf(tw, "🌡️  Temperature:\t%.1f °C\n", w.Main.Temp)
-	fmt.Fprintf(tw, "🤔  Feels like:\t%.1f °C\n", w.Main.FeelsLike)
-	fmt.Fprintf(tw, "💧  Humidity:\t%d%%\n", w.Main.Humidity)
-	fmt.Fprintf(tw, "💨  Wind:\t%.1f m/s\n", w.Wind.Speed)
-	fmt.Fprintf(tw, "📋  Condition:\t%s (%s)\n", condition, description)
-	tw.Flush()
+// coordsGiven reports whether -lat and -lon were set on the command line.
+// Setting only one of them is an error.
+func coordsGiven() (bool, error) {
+	set := map[string]bool{}
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["lat"] != set["lon"] {
+		return false, fmt.Errorf("-lat and -lon must be used together")
+	}
+	return set["lat"], nil
+}
 
-	fmt.Println()
+// resolveAPIKey returns the API key following the priority chain:
+// flag > environment variable > empty string.
+func resolveAPIKey(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	return os.Getenv("OWM_API_KEY")
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/weather-cli/internal/weather"
+)
+
+func TestSplitCities(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Almaty", []string{"Almaty"}},
+		{"Almaty, London ,Tokyo", []string{"Almaty", "London", "Tokyo"}},
+		{"London,GB,Paris,FR", []string{"London,GB", "Paris,FR"}},
+		{"Portland,OR,US, Almaty", []string{"Portland,OR,US", "Almaty"}},
+		{" , ,", nil},
+	}
+	for _, tc := range tests {
+		if got := splitCities(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitCities(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+// fakeProvider serves canned weather by city name.
+type fakeProvider map[string]float64
+
+func (f fakeProvider) FetchWeather(ctx context.Context, city string) (*weather.WeatherResponse, error) {
+	temp, ok := f[city]
+	if !ok {
+		return nil, errors.New("city not found")
+	}
+	w := &weather.WeatherResponse{Name: city}
+	w.Main.Temp = temp
+	return w, nil
+}
+
+func TestFetchCitiesWithFakeProvider(t *testing.T) {
+	p := fakeProvider{"Almaty": -5.2, "London": 8}
+
+	reports, errs := fetchCities(context.Background(), p, []string{"Almaty", "Atlantis", "London"})
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "Atlantis") {
+		t.Errorf("expected one error naming Atlantis, got %v", errs)
+	}
+	if len(reports) != 2 || reports[0].Weather.Name != "Almaty" || reports[1].Weather.Name != "London" {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, reports, weather.UnitsMetric); err != nil {
+		t.Fatalf("writeJSON: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"temp": -5.2`) {
+		t.Errorf("expected fake temperature in output, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/weather-cli/internal/weather"
+)
+
+func weatherEmoji(condition string) string {
+	switch condition {
+	case "Clear":
+		return "☀️"
+	case "Clouds":
+		return "☁️"
+	case "Rain", "Drizzle":
+		return "🌧️"
+	case "Thunderstorm":
+		return "⛈️"
+	case "Snow":
+		return "❄️"
+	case "Mist", "Fog", "Haze":
+		return "🌫️"
+	default:
+		return "🌡️"
+	}
+}
+
+// unitLabels returns the temperature and wind speed labels for units.
+func unitLabels(units string) (temp, speed string) {
+	if units == weather.UnitsImperial {
+		return "°F", "mph"
+	}
+	return "°C", "m/s"
+}
+
+var compassPoints = [...]string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// compass maps a bearing in degrees to the nearest of the 16 compass
+// points. Bearings outside 0–359 are wrapped, so 360 is "N" again.
+func compass(deg int) string {
+	deg %= 360
+	if deg < 0 {
+		deg += 360
+	}
+	// Each point covers 22.5°, centered on its bearing.
+	i := (deg*10 + 112) / 225 % len(compassPoints)
+	return compassPoints[i]
+}
+
+// localTimeLayout shows the clock time followed by the UTC offset, e.g.
+// "22:30 (UTC-03:30)".
+const localTimeLayout = "15:04 (UTC-07:00)"
+
+// formatLocalTime returns now as seen in w's city.
+func formatLocalTime(w *weather.WeatherResponse, now time.Time) string {
+	return w.LocalTime(now).Format(localTimeLayout)
+}
+
+// conditionsUnavailable is shown when the API returns no weather conditions.
+const conditionsUnavailable = "conditions unavailable"
+
+// report is everything shown for one location.
+type report struct {
+	Weather *weather.WeatherResponse
+	Air     *weather.AirQualityResponse // nil unless -air
+	UV      *weather.UVResponse         // nil unless -uv
+}
+
+// printWeather writes r to out as a human-readable table.
+func printWeather(out io.Writer, r report, units string) {
+	w := r.Weather
+	condition, description, hasCondition := w.Condition()
+
+	emoji := weatherEmoji(condition)
+
+	fmt.Fprintf(out, "\n%s  Weather in %s, %s\n", emoji, w.Name, w.Sys.Country)
+	fmt.Fprintln(out, "─────────────────────────────────")
+
+	tempUnit, speedUnit := unitLabels(units)
+
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "🌡️  Temperature:\t%.1f %s\n", w.Main.Temp, tempUnit)
+	fmt.Fprintf(tw, "🤔  Feels like:\t%.1f %s\n", w.Main.FeelsLike, tempUnit)
+	fmt.Fprintf(tw, "💧  Humidity:\t%d%%\n", w.Main.Humidity)
+	fmt.Fprintf(tw, "💨  Wind:\t%.1f %s %s\n", w.Wind.Speed, speedUnit, compass(w.Wind.Deg))
+	if w.Main.Pressure != 0 {
+		fmt.Fprintf(tw, "🔽  Pressure:\t%d hPa\n", w.Main.Pressure)
+	}
+	if w.Visibility != nil {
+		fmt.Fprintf(tw, "👁️  Visibility:\t%.1f km\n", float64(*w.Visibility)/1000)
+	} else {
+		fmt.Fprintf(tw, "👁️  Visibility:\tn/a\n")
+	}
+	if hasCondition {
+		fmt.Fprintf(tw, "📋  Condition:\t%s (%s)\n", condition, description)
+	} else {
+		fmt.Fprintf(tw, "📋  Condition:\t%s\n", conditionsUnavailable)
+	}
+	fmt.Fprintf(tw, "🕒  Local time:\t%s\n", formatLocalTime(w, time.Now()))
+	if t, ok := w.SunriseLocal(); ok {
+		fmt.Fprintf(tw, "🌅  Sunrise:\t%s\n", t.Format("15:04"))
+	}
+	if t, ok := w.SunsetLocal(); ok {
+		fmt.Fprintf(tw, "🌇  Sunset:\t%s\n", t.Format("15:04"))
+	}
+	if r.Air != nil {
+		if aqi, ok := r.Air.AQI(); ok {
+			fmt.Fprintf(tw, "🏭  Air quality:\t%d (%s)\n", aqi, weather.AQILabel(aqi))
+		}
+	}
+	if r.UV != nil {
+		fmt.Fprintf(tw, "🕶️  UV index:\t%.1f (%s)\n", r.UV.UVIndex, weather.UVLabel(r.UV.UVIndex))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(out)
+}
+
+// summary is the flattened, script-friendly form of a weather report
+// printed by -json.
+type summary struct {
+	City        string   `json:"city"`
+	Country     string   `json:"country"`
+	Units       string   `json:"units"`
+	Temp        float64  `json:"temp"`
+	FeelsLike   float64  `json:"feels_like"`
+	TempMin     float64  `json:"temp_min"`
+	TempMax     float64  `json:"temp_max"`
+	Humidity    int      `json:"humidity"`
+	WindSpeed   float64  `json:"wind_speed"`
+	WindDeg     int      `json:"wind_deg"`
+	WindDir     string   `json:"wind_dir"`
+	Pressure    int      `json:"pressure,omitempty"`     // hPa
+	Visibility  *int     `json:"visibility_m,omitempty"` // meters
+	Condition   string   `json:"condition"`
+	Description string   `json:"description"`
+	LocalTime   string   `json:"local_time"`        // RFC 3339 in the city's local time
+	Sunrise     string   `json:"sunrise,omitempty"` // RFC 3339 in the city's local time
+	Sunset      string   `json:"sunset,omitempty"`
+	AQI         int      `json:"aqi,omitempty"`
+	AQILabel    string   `json:"aqi_label,omitempty"`
+	UVIndex     *float64 `json:"uv_index,omitempty"`
+	UVLabel     string   `json:"uv_label,omitempty"`
+}
+
+// newSummary flattens r; units records which system the numbers are in.
+func newSummary(r report, units string) summary {
+	w := r.Weather
+	s := summary{
+		City:       w.Name,
+		Country:    w.Sys.Country,
+		Units:      units,
+		Temp:       w.Main.Temp,
+		FeelsLike:  w.Main.FeelsLike,
+		TempMin:    w.Main.TempMin,
+		TempMax:    w.Main.TempMax,
+		Humidity:   w.Main.Humidity,
+		WindSpeed:  w.Wind.Speed,
+		WindDeg:    w.Wind.Deg,
+		WindDir:    compass(w.Wind.Deg),
+		Pressure:   w.Main.Pressure,
+		Visibility: w.Visibility,
+		LocalTime:  w.LocalTime(time.Now()).Format(time.RFC3339),
+	}
+	s.Condition, s.Description, _ = w.Condition()
+	if t, ok := w.SunriseLocal(); ok {
+		s.Sunrise = t.Format(time.RFC3339)
+	}
+	if t, ok := w.SunsetLocal(); ok {
+		s.Sunset = t.Format(time.RFC3339)
+	}
+	if r.Air != nil {
+		if aqi, ok := r.Air.AQI(); ok {
+			s.AQI = aqi
+			s.AQILabel = weather.AQILabel(aqi)
+		}
+	}
+	if r.UV != nil {
+		s.UVIndex = &r.UV.UVIndex
+		s.UVLabel = weather.UVLabel(r.UV.UVIndex)
+	}
+	return s
+}
+
+// writeJSON writes the reports to out as an indented JSON array, even when
+// there is only one report, so consumers always get the same shape.
+func writeJSON(out io.Writer, reports []report, units string) error {
+	summaries := make([]summary, len(reports))
+	for i, r := range reports {
+		summaries[i] = newSummary(r, units)
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(summaries)
+}
+
+// writeReports writes the reports to out as JSON or as one table per report.
+func writeReports(out io.Writer, reports []report, units string, asJSON bool) error {
+	if asJSON {
+		return writeJSON(out, reports, units)
+	}
+	for _, r := range reports {
+		printWeather(out, r, units)
+	}
+	return nil
+}
+
+// appendReports writes the reports to the file at path, creating it if
+// needed and appending to whatever it already holds.
+func appendReports(path string, reports []report, units string, asJSON bool) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open output: %w", err)
+	}
+	if err := writeReports(f, reports, units, asJSON); err != nil {
+		f.Close()
+		return fmt.Errorf("write output: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/weather-cli/internal/weather"
+)
+
+// sampleReport decodes a trimmed OpenWeatherMap payload.
+func sampleReport(t *testing.T, city string) report {
+	t.Helper()
+	payload := `{
+		"name": "` + city + `",
+		"sys": {"country": "KZ"},
+		"main": {"temp": -5.2, "feels_like": -9.8, "humidity": 72, "temp_min": -7, "temp_max": -3},
+		"wind": {"speed": 3.5, "deg": 200},
+		"weather": [{"main": "Clouds", "description": "overcast clouds"}]
+	}`
+	var w weather.WeatherResponse
+	if err := json.Unmarshal([]byte(payload), &w); err != nil {
+		t.Fatalf("decode sample: %v", err)
+	}
+	return report{Weather: &w}
+}
+
+func TestWriteJSONSingle(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, []report{sampleReport(t, "Almaty")}, weather.UnitsMetric); err != nil {
+		t.Fatalf("writeJSON: %v", err)
+	}
+
+	var list []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &list); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(list) != 1 {
+		t.Fatalf("expected one summary, got %d", len(list))
+	}
+	got := list[0]
+	want := map[string]any{
+		"city":        "Almaty",
+		"country":     "KZ",
+		"units":       "metric",
+		"temp":        -5.2,
+		"humidity":    float64(72),
+		"wind_speed":  3.5,
+		"wind_dir":    "SSW",
+		"condition":   "Clouds",
+		"description": "overcast clouds",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s: expected %v, got %v", k, v, got[k])
+		}
+	}
+}
+
+func TestWriteJSONMany(t *testing.T) {
+	reports := []report{sampleReport(t, "Almaty"), sampleReport(t, "Astana")}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, reports, weather.UnitsImperial); err != nil {
+		t.Fatalf("writeJSON: %v", err)
+	}
+
+	var got []summary
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(got) != 2 || got[0].City != "Almaty" || got[1].City != "Astana" || got[1].Units != "imperial" {
+		t.Errorf("unexpected summaries: %+v", got)
+	}
+}
+
+func TestCompass(t *testing.T) {
+	tests := []struct {
+		deg  int
+		want string
+	}{
+		{0, "N"},
+		{11, "N"},
+		{12, "NNE"},
+		{45, "NE"},
+		{90, "E"},
+		{135, "SE"},
+		{180, "S"},
+		{200, "SSW"},
+		{225, "SW"},
+		{270, "W"},
+		{315, "NW"},
+		{348, "NNW"},
+		{349, "N"},
+		{359, "N"},
+		{360, "N"},
+		{450, "E"},
+		{-90, "W"},
+	}
+	for _, tt := range tests {
+		if got := compass(tt.deg); got != tt.want {
+			t.Errorf("compass(%d) = %q, want %q", tt.deg, got, tt.want)
+		}
+	}
+}
+
+func TestAppendReports(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weather.log")
+
+	if err := appendReports(path, []report{sampleReport(t, "Almaty")}, weather.UnitsMetric, false); err != nil {
+		t.Fatalf("appendReports table: %v", err)
+	}
+	if err := appendReports(path, []report{sampleReport(t, "Astana")}, weather.UnitsMetric, true); err != nil {
+		t.Fatalf("appendReports json: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	got := string(data)
+	table, js, ok := strings.Cut(got, "[\n")
+	if !ok {
+		t.Fatalf("expected JSON appended after the table, got:\n%s", got)
+	}
+	if !strings.Contains(table, "Weather in Almaty, KZ") || !strings.Contains(table, "-5.2 °C") {
+		t.Errorf("table output missing expected lines:\n%s", table)
+	}
+
+	var s []summary
+	if err := json.Unmarshal([]byte("[\n"+js), &s); err != nil {
+		t.Fatalf("appended JSON does not decode: %v\n%s", err, js)
+	}
+	if len(s) != 1 || s[0].City != "Astana" {
+		t.Errorf("expected appended city Astana, got %+v", s)
+	}
+}
+
+func TestFormatLocalTime(t *testing.T) {
+	now := time.Date(2024, 1, 15, 2, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		offset int
+		want   string
+	}{
+		{"UTC", 0, "02:00 (UTC+00:00)"},
+		{"Almaty", 5 * 3600, "07:00 (UTC+05:00)"},
+		{"Kathmandu", 5*3600 + 45*60, "07:45 (UTC+05:45)"},
+		{"New York", -5 * 3600, "21:00 (UTC-05:00)"},
+		{"St. John's", -(3*3600 + 30*60), "22:30 (UTC-03:30)"},
+	}
+	for _, tt := range tests {
+		w := &weather.WeatherResponse{Timezone: tt.offset}
+		if got := formatLocalTime(w, now); got != tt.want {
+			t.Errorf("%s: formatLocalTime = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPrintWeatherEmptyConditions(t *testing.T) {
+	var w weather.WeatherResponse
+	payload := `{"name": "Almaty", "sys": {"country": "KZ"}, "main": {"temp": 20}, "weather": []}`
+	if err := json.Unmarshal([]byte(payload), &w); err != nil {
+		t.Fatalf("decode sample: %v", err)
+	}
+
+	var buf bytes.Buffer
+	printWeather(&buf, report{Weather: &w}, weather.UnitsMetric)
+	out := buf.String()
+	if !strings.Contains(out, conditionsUnavailable) {
+		t.Errorf("expected %q in output:\n%s", conditionsUnavailable, out)
+	}
+	if strings.Contains(out, "()") {
+		t.Errorf("expected no empty description in output:\n%s", out)
+	}
+
+	s := newSummary(report{Weather: &w}, weather.UnitsMetric)
+	if s.Condition != "" || s.Description != "" {
+		t.Errorf("expected empty condition in summary, got %q (%q)", s.Condition, s.Description)
+	}
+}
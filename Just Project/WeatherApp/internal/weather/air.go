@@ -0,0 +1,58 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+const airPollutionURL = "https://api.openweathermap.org/data/2.5/air_pollution"
+
+// FetchAirQuality requests the current air pollution data at the given
+// latitude and longitude.
+func (c *Client) FetchAirQuality(ctx context.Context, lat, lon float64) (*AirQualityResponse, error) {
+	u, err := url.Parse(c.airURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse air quality url: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	q.Set("lon", strconv.FormatFloat(lon, 'f', -1, 64))
+	q.Set("appid", c.apiKey)
+	u.RawQuery = q.Encode()
+
+	var air AirQualityResponse
+	if err := c.get(ctx, u.String(), &air); err != nil {
+		return nil, err
+	}
+	return &air, nil
+}
+
+// AQI returns the air quality index (1 = Good … 5 = Very Poor) of the
+// current reading, and false if the response holds no readings.
+func (a *AirQualityResponse) AQI() (int, bool) {
+	if len(a.List) == 0 {
+		return 0, false
+	}
+	return a.List[0].Main.AQI, true
+}
+
+// AQILabel names an OpenWeatherMap air quality index value.
+func AQILabel(aqi int) string {
+	switch aqi {
+	case 1:
+		return "Good"
+	case 2:
+		return "Fair"
+	case 3:
+		return "Moderate"
+	case 4:
+		return "Poor"
+	case 5:
+		return "Very Poor"
+	default:
+		return "Unknown"
+	}
+}
@@ -0,0 +1,57 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const airPayload = `{
+	"coord": {"lon": 76.9286, "lat": 43.2567},
+	"list": [{
+		"dt": 1700000000,
+		"main": {"aqi": 3},
+		"components": {"co": 201.94, "no": 0.02, "no2": 0.77, "o3": 68.66, "so2": 0.64, "pm2_5": 12.5, "pm10": 20.1, "nh3": 0.12}
+	}]
+}`
+
+func TestFetchAirQuality(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("lat") != "43.2567" || q.Get("lon") != "76.9286" {
+			t.Errorf("unexpected coords lat=%s lon=%s", q.Get("lat"), q.Get("lon"))
+		}
+		if got := q.Get("appid"); got != testAPIKey {
+			t.Errorf("expected appid=%s, got %s", testAPIKey, got)
+		}
+		w.Write([]byte(airPayload))
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv.URL)
+	client.airURL = srv.URL
+
+	got, err := client.FetchAirQuality(context.Background(), 43.2567, 76.9286)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	aqi, ok := got.AQI()
+	if !ok || aqi != 3 {
+		t.Errorf("expected AQI 3, got %d (ok=%v)", aqi, ok)
+	}
+	if label := AQILabel(aqi); label != "Moderate" {
+		t.Errorf("expected label Moderate, got %s", label)
+	}
+	c := got.List[0].Components
+	if c.PM25 != 12.5 || c.PM10 != 20.1 || c.CO != 201.94 {
+		t.Errorf("unexpected components: %+v", c)
+	}
+}
+
+func TestAirQualityEmptyList(t *testing.T) {
+	var air AirQualityResponse
+	if _, ok := air.AQI(); ok {
+		t.Error("expected no AQI for empty list")
+	}
+}
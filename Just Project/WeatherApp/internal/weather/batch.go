@@ -0,0 +1,54 @@
+package weather
+
+import (
+	"context"
+	"sync"
+)
+
+// CityResult is the outcome of fetching one city in FetchWeatherMany.
+type CityResult struct {
+	City    string
+	Weather *WeatherResponse
+	Err     error
+}
+
+// FetchWeatherMany fetches the weather for each city concurrently; see
+// FetchMany.
+func (c *Client) FetchWeatherMany(ctx context.Context, cities []string, limit int) []CityResult {
+	return FetchMany(ctx, c, cities, limit)
+}
+
+// FetchMany fetches the weather for each city from p concurrently, running
+// at most limit requests at a time. All requests share ctx, so one deadline
+// bounds the whole batch. A failure for one city doesn't stop the others;
+// it is reported in that city's result. Results are in the order of cities.
+func FetchMany(ctx context.Context, p Provider, cities []string, limit int) []CityResult {
+	if limit < 1 {
+		limit = 1
+	}
+
+	results := make([]CityResult, len(cities))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, city := range cities {
+		wg.Add(1)
+		go func(i int, city string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = CityResult{City: city, Err: ctx.Err()}
+				return
+			}
+
+			w, err := p.FetchWeather(ctx, city)
+			results[i] = CityResult{City: city, Weather: w, Err: err}
+		}(i, city)
+	}
+
+	wg.Wait()
+	return results
+}
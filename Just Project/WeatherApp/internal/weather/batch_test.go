@@ -0,0 +1,64 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchWeatherMany(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		city := r.URL.Query().Get("q")
+		if city == "Atlantis" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(APIError{Cod: "404", Message: "city not found"})
+			return
+		}
+		resp := successResponse()
+		resp.Name = city
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	cities := []string{"Almaty", "London", "Atlantis", "Tokyo", "Paris"}
+	results := newTestClient(srv.URL).FetchWeatherMany(context.Background(), cities, 2)
+
+	if len(results) != len(cities) {
+		t.Fatalf("expected %d results, got %d", len(cities), len(results))
+	}
+	for i, res := range results {
+		if res.City != cities[i] {
+			t.Errorf("result %d: expected city %s, got %s", i, cities[i], res.City)
+		}
+		if res.City == "Atlantis" {
+			if res.Err == nil {
+				t.Error("expected error for Atlantis")
+			}
+			continue
+		}
+		if res.Err != nil {
+			t.Errorf("%s: unexpected error: %v", res.City, res.Err)
+		} else if res.Weather.Name != res.City {
+			t.Errorf("%s: got weather for %s", res.City, res.Weather.Name)
+		}
+	}
+	if got := maxInFlight.Load(); got > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", got)
+	}
+}
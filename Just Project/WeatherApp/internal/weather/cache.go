@@ -0,0 +1,47 @@
+package weather
+
+import (
+	"sync"
+	"time"
+)
+
+// cache is a concurrency-safe in-memory store of weather responses that
+// expire after a TTL.
+type cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time // overridable for testing
+}
+
+type cacheEntry struct {
+	weather WeatherResponse
+	expires time.Time
+}
+
+func newCache() *cache {
+	return &cache{entries: make(map[string]cacheEntry), now: time.Now}
+}
+
+// get returns a copy of the response stored under key if it hasn't expired.
+func (c *cache) get(key string) (*WeatherResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	w := e.weather
+	return &w, true
+}
+
+// put stores a copy of w under key for ttl.
+func (c *cache) put(key string, w *WeatherResponse, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{weather: *w, expires: c.now().Add(ttl)}
+}
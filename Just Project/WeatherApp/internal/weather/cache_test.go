@@ -0,0 +1,62 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFetchWeatherCached(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		json.NewEncoder(w).Encode(successResponse())
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv.URL)
+	client.CacheTTL = time.Minute
+
+	for i := 0; i < 2; i++ {
+		got, err := client.FetchWeather(context.Background(), "Almaty")
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		if got.Name != "Almaty" {
+			t.Errorf("call %d: expected Almaty, got %s", i, got.Name)
+		}
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("expected 1 HTTP request within TTL, got %d", n)
+	}
+
+	// Different units are a different cache entry.
+	client.Units = UnitsImperial
+	if _, err := client.FetchWeather(context.Background(), "Almaty"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := hits.Load(); n != 2 {
+		t.Errorf("expected a new request for other units, got %d total", n)
+	}
+}
+
+func TestCacheExpires(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := newCache()
+	c.now = func() time.Time { return now }
+
+	w := successResponse()
+	c.put("k", &w, time.Minute)
+	if _, ok := c.get("k"); !ok {
+		t.Fatal("expected fresh entry")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := c.get("k"); ok {
+		t.Error("expected entry to expire after TTL")
+	}
+}
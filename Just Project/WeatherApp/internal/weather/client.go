@@ -3,19 +3,58 @@ package weather
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 )
 
 const baseURL = "https://api.openweathermap.org/data/2.5/weather"
 
+// Units systems supported by the API.
+const (
+	UnitsMetric   = "metric"   // °C, m/s
+	UnitsImperial = "imperial" // °F, mph
+)
+
+// Provider is a source of current weather by city name. Client implements
+// it for OpenWeatherMap; other backends or test fakes can be swapped in.
+type Provider interface {
+	FetchWeather(ctx context.Context, city string) (*WeatherResponse, error)
+}
+
+// Client implements Provider.
+var _ Provider = (*Client)(nil)
+
 // Client wraps an HTTP client configured for OpenWeatherMap API.
 type Client struct {
+	// Units selects the measurement system: UnitsMetric (default) or UnitsImperial.
+	Units string
+	// Lang is the language code for weather descriptions, e.g. "en" (default), "ru", "de".
+	Lang string
+	// CacheTTL is how long successful responses are reused for the same
+	// location and units. Zero disables caching.
+	CacheTTL time.Duration
+	// MaxAttempts bounds how many times a request is tried when it fails
+	// with a 5xx response or a transport error.
+	MaxAttempts int
+	// RetryBackoff is the wait before the first retry; it doubles after
+	// each further failure.
+	RetryBackoff time.Duration
+	// Verbose, when set, receives one line per HTTP request with the URL
+	// (API key redacted) and the response status.
+	Verbose io.Writer
+
 	apiKey     string
 	httpClient *http.Client
 	baseURL    string // overridable for testing
+	airURL     string // overridable for testing
+	uvURL      string // overridable for testing
+	cache      *cache
 }
 
 // NewClient creates a Client with an explicit timeout instead of http.DefaultClient.
@@ -26,47 +65,270 @@ func NewClient(apiKey string, timeout time.Duration) *Client {
 			Timeout: timeout,
 		},
 		baseURL: baseURL,
+		airURL:  airPollutionURL,
+		uvURL:   uvIndexURL,
+		Units:   UnitsMetric,
+		Lang:    "en",
+		cache:   newCache(),
+
+		MaxAttempts:  3,
+		RetryBackoff: 200 * time.Millisecond,
 	}
 }
 
+// ValidUnits reports whether units is a measurement system the API accepts.
+func ValidUnits(units string) bool {
+	return units == UnitsMetric || units == UnitsImperial
+}
+
 // FetchWeather requests current weather for the given city.
 // The context allows the caller (e.g. main) to enforce cancellation or deadline.
+// When the API does not know the city, the error suggests the closest
+// common city name, if any is close.
 func (c *Client) FetchWeather(ctx context.Context, city string) (*WeatherResponse, error) {
+	q := url.Values{}
+	q.Set("q", city)
+	w, err := c.fetch(ctx, q)
+	if err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+			if s, ok := suggestCity(city); ok {
+				return nil, fmt.Errorf("%w; did you mean %q?", err, s)
+			}
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+// FetchWeatherByCoords requests current weather at the given latitude and
+// longitude, which avoids ambiguous city names.
+func (c *Client) FetchWeatherByCoords(ctx context.Context, lat, lon float64) (*WeatherResponse, error) {
+	q := url.Values{}
+	q.Set("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	q.Set("lon", strconv.FormatFloat(lon, 'f', -1, 64))
+	return c.fetch(ctx, q)
+}
+
+// FetchWeatherByZip requests current weather for a ZIP or postal code.
+// country is an ISO 3166 country code; the API assumes the US when empty.
+func (c *Client) FetchWeatherByZip(ctx context.Context, zip, country string) (*WeatherResponse, error) {
+	if country != "" {
+		zip += "," + country
+	}
+	q := url.Values{}
+	q.Set("zip", zip)
+	return c.fetch(ctx, q)
+}
+
+// fetch returns the weather for the location query params in loc, from the
+// cache when a fresh entry exists and from the API otherwise.
+func (c *Client) fetch(ctx context.Context, loc url.Values) (*WeatherResponse, error) {
+	if c.CacheTTL <= 0 {
+		return c.request(ctx, loc)
+	}
+
+	key := loc.Encode() + "|" + c.units() + "|" + c.lang()
+	if w, ok := c.cache.get(key); ok {
+		return w, nil
+	}
+	w, err := c.request(ctx, loc)
+	if err != nil {
+		return nil, err
+	}
+	c.cache.put(key, w, c.CacheTTL)
+	return w, nil
+}
+
+// request performs the API request with the location query params in loc,
+// adding the key, units and language.
+func (c *Client) request(ctx context.Context, loc url.Values) (*WeatherResponse, error) {
 	u, err := url.Parse(c.baseURL)
 	if err != nil {
 		return nil, fmt.Errorf("parse base url: %w", err)
 	}
 
 	q := u.Query()
-	q.Set("q", city)
+	for k, v := range loc {
+		q[k] = v
+	}
 	q.Set("appid", c.apiKey)
-	q.Set("units", "metric")
-	q.Set("lang", "en")
+	q.Set("units", c.units())
+	q.Set("lang", c.lang())
 	u.RawQuery = q.Encode()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	var weather WeatherResponse
+	if err := c.get(ctx, u.String(), &weather); err != nil {
+		return nil, err
+	}
+	return &weather, nil
+}
+
+// get performs a GET of rawURL and decodes the JSON body into out. Transport
+// errors and 5xx responses are retried up to MaxAttempts times with
+// exponential backoff; other API errors are returned at once. Retrying stops
+// early if the context is done or its deadline would pass during the wait.
+//
+// A 429 response is retried once after its Retry-After delay, if that fits
+// within the context deadline; otherwise a "rate limited" error is returned.
+func (c *Client) get(ctx context.Context, rawURL string, out any) error {
+	attempts := c.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	rateLimitRetried := false
+	for attempt := 1; ; attempt++ {
+		err := c.getOnce(ctx, rawURL, out)
+		if err == nil {
+			return nil
+		}
+
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+			if rateLimitRetried || httpErr.RetryAfter <= 0 || !sleep(ctx, httpErr.RetryAfter) {
+				return rateLimited(httpErr)
+			}
+			rateLimitRetried = true
+			attempt-- // waiting out the rate limit doesn't use up an attempt
+			continue
+		}
+
+		if attempt == attempts || !retryable(ctx, err) {
+			return err
+		}
+		if !sleep(ctx, c.RetryBackoff<<(attempt-1)) {
+			return err
+		}
+	}
+}
+
+// sleep waits for d and reports whether it did. It returns false at once if
+// the context's deadline would pass first, or early if the context is done.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// rateLimited wraps a 429 error with a message saying when to retry.
+func rateLimited(httpErr *HTTPError) error {
+	if httpErr.RetryAfter <= 0 {
+		return fmt.Errorf("rate limited, retry later: %w", httpErr)
+	}
+	secs := int(math.Ceil(httpErr.RetryAfter.Seconds()))
+	return fmt.Errorf("rate limited, retry after %ds: %w", secs, httpErr)
+}
+
+// parseRetryAfter reads a Retry-After header given either as seconds or as
+// an HTTP date. It returns 0 if the header is missing or invalid.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// retryable reports whether err is worth another attempt: a 5xx response or
+// a transport error that isn't caused by the context ending.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500
+	}
+	return true
+}
+
+// getOnce performs a single GET of rawURL, decoding the body into out.
+func (c *Client) getOnce(ctx context.Context, rawURL string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return fmt.Errorf("create request: %w", err)
 	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("execute request: %w", err)
+		c.logf("GET %s -> %v", redactKey(rawURL), err)
+		return fmt.Errorf("execute request: %w", err)
 	}
 	defer resp.Body.Close()
+	c.logf("GET %s -> %s", redactKey(rawURL), resp.Status)
 
 	if resp.StatusCode != http.StatusOK {
+		httpErr := &HTTPError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
 		var apiErr APIError
 		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
-			return nil, fmt.Errorf("API error (HTTP %d): unable to decode body", resp.StatusCode)
+			httpErr.Message = "unable to decode body"
+		} else {
+			httpErr.Message = apiErr.Message
 		}
-		return nil, fmt.Errorf("API error (HTTP %d): %s", resp.StatusCode, apiErr.Message)
+		return httpErr
 	}
 
-	var weather WeatherResponse
-	if err := json.NewDecoder(resp.Body).Decode(&weather); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
 	}
+	return nil
+}
 
-	return &weather, nil
+// logf writes a line to c.Verbose, if set.
+func (c *Client) logf(format string, args ...any) {
+	if c.Verbose != nil {
+		fmt.Fprintf(c.Verbose, format+"\n", args...)
+	}
+}
+
+// redactKey returns rawURL with the appid query value masked.
+func redactKey(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "(invalid url)"
+	}
+	q := u.Query()
+	if q.Has("appid") {
+		q.Set("appid", "REDACTED")
+		u.RawQuery = q.Encode()
+	}
+	return u.String()
+}
+
+// lang returns the configured language, falling back to English.
+func (c *Client) lang() string {
+	if c.Lang == "" {
+		return "en"
+	}
+	return c.Lang
+}
+
+// units returns the configured units, falling back to metric.
+func (c *Client) units() string {
+	if c.Units == "" {
+		return UnitsMetric
+	}
+	return c.Units
 }
@@ -3,8 +3,11 @@ package weather
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -17,6 +20,8 @@ func successResponse() WeatherResponse {
 		Name: "Almaty",
 		Sys: struct {
 			Country string `json:"country"`
+			Sunrise int64  `json:"sunrise"`
+			Sunset  int64  `json:"sunset"`
 		}{Country: "KZ"},
 		Main: struct {
 			Temp      float64 `json:"temp"`
@@ -24,16 +29,19 @@ func successResponse() WeatherResponse {
 			Humidity  int     `json:"humidity"`
 			TempMin   float64 `json:"temp_min"`
 			TempMax   float64 `json:"temp_max"`
+			Pressure  int     `json:"pressure"`
 		}{
 			Temp:      -5.2,
 			FeelsLike: -9.8,
 			Humidity:  72,
 			TempMin:   -7.0,
 			TempMax:   -3.0,
+			Pressure:  1021,
 		},
 		Wind: struct {
 			Speed float64 `json:"speed"`
-		}{Speed: 3.5},
+			Deg   int     `json:"deg"`
+		}{Speed: 3.5, Deg: 200},
 		Weather: []struct {
 			Main        string `json:"main"`
 			Description string `json:"description"`
@@ -46,6 +54,7 @@ func successResponse() WeatherResponse {
 func newTestClient(baseURL string) *Client {
 	client := NewClient(testAPIKey, 5*time.Second)
 	client.baseURL = baseURL
+	client.RetryBackoff = time.Millisecond
 	return client
 }
 
@@ -167,3 +176,298 @@ func TestFetchWeatherContextCancelled(t *testing.T) {
 		t.Fatal("expected error for cancelled context, got nil")
 	}
 }
+
+func TestFetchWeatherImperialUnits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("units"); got != UnitsImperial {
+			t.Errorf("expected units=imperial, got %s", got)
+		}
+		json.NewEncoder(w).Encode(successResponse())
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv.URL)
+	client.Units = UnitsImperial
+
+	if _, err := client.FetchWeather(context.Background(), "Almaty"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFetchWeatherByCoords(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got := q.Get("lat"); got != "43.2567" {
+			t.Errorf("expected lat=43.2567, got %s", got)
+		}
+		if got := q.Get("lon"); got != "76.9286" {
+			t.Errorf("expected lon=76.9286, got %s", got)
+		}
+		if q.Has("q") {
+			t.Errorf("expected no q param, got %s", q.Get("q"))
+		}
+		json.NewEncoder(w).Encode(successResponse())
+	}))
+	defer srv.Close()
+
+	got, err := newTestClient(srv.URL).FetchWeatherByCoords(context.Background(), 43.2567, 76.9286)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Name != "Almaty" || got.Main.Temp != -5.2 {
+		t.Errorf("unexpected response: %+v", got)
+	}
+}
+
+func TestFetchWeatherRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(successResponse())
+	}))
+	defer srv.Close()
+
+	got, err := newTestClient(srv.URL).FetchWeather(context.Background(), "Almaty")
+	if err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if got.Name != "Almaty" {
+		t.Errorf("expected Almaty, got %s", got.Name)
+	}
+	if n := calls.Load(); n != 3 {
+		t.Errorf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestFetchWeatherDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIError{Cod: "404", Message: "city not found"})
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv.URL).FetchWeather(context.Background(), "Nowhere")
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected HTTP 404 error, got %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected a single attempt for 404, got %d", n)
+	}
+}
+
+func TestFetchWeatherGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv.URL)
+	client.MaxAttempts = 4
+	if _, err := client.FetchWeather(context.Background(), "Almaty"); err == nil {
+		t.Fatal("expected error after exhausting retries")
+	}
+	if n := calls.Load(); n != 4 {
+		t.Errorf("expected 4 attempts, got %d", n)
+	}
+}
+
+func TestSunriseSunsetLocal(t *testing.T) {
+	payload := `{
+		"name": "Almaty",
+		"timezone": 18000,
+		"sys": {"country": "KZ", "sunrise": 1700016120, "sunset": 1700051280}
+	}`
+	var w WeatherResponse
+	if err := json.Unmarshal([]byte(payload), &w); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	sunrise, ok := w.SunriseLocal()
+	if !ok || sunrise.Format("15:04") != "07:42" {
+		t.Errorf("expected sunrise 07:42 at UTC+5, got %s (ok=%v)", sunrise.Format("15:04"), ok)
+	}
+	sunset, ok := w.SunsetLocal()
+	if !ok || sunset.Format("15:04") != "17:28" {
+		t.Errorf("expected sunset 17:28 at UTC+5, got %s (ok=%v)", sunset.Format("15:04"), ok)
+	}
+
+	var empty WeatherResponse
+	if _, ok := empty.SunriseLocal(); ok {
+		t.Error("expected no sunrise when the field is missing")
+	}
+}
+
+func TestFetchWeatherLang(t *testing.T) {
+	for _, lang := range []string{"", "ru"} {
+		want := lang
+		if want == "" {
+			want = "en"
+		}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.URL.Query().Get("lang"); got != want {
+				t.Errorf("expected lang=%s, got %s", want, got)
+			}
+			json.NewEncoder(w).Encode(successResponse())
+		}))
+
+		client := newTestClient(srv.URL)
+		if lang != "" {
+			client.Lang = lang
+		}
+		if _, err := client.FetchWeather(context.Background(), "Almaty"); err != nil {
+			t.Errorf("lang %q: unexpected error: %v", lang, err)
+		}
+		srv.Close()
+	}
+}
+
+func TestFetchWeatherByZip(t *testing.T) {
+	tests := []struct {
+		zip, country, want string
+	}{
+		{"94040", "us", "94040,us"},
+		{"SW1A 1AA", "gb", "SW1A 1AA,gb"},
+		{"10001", "", "10001"},
+	}
+	for _, tc := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query()
+			if got := q.Get("zip"); got != tc.want {
+				t.Errorf("expected zip=%q, got %q", tc.want, got)
+			}
+			if q.Has("q") {
+				t.Errorf("expected no q param, got %s", q.Get("q"))
+			}
+			json.NewEncoder(w).Encode(successResponse())
+		}))
+
+		if _, err := newTestClient(srv.URL).FetchWeatherByZip(context.Background(), tc.zip, tc.country); err != nil {
+			t.Errorf("zip %q: unexpected error: %v", tc.zip, err)
+		}
+		srv.Close()
+	}
+}
+
+func TestPressureAndVisibility(t *testing.T) {
+	var w WeatherResponse
+	payload := `{"main": {"temp": 1.5, "pressure": 1013}, "visibility": 8500}`
+	if err := json.Unmarshal([]byte(payload), &w); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if w.Main.Pressure != 1013 {
+		t.Errorf("expected pressure 1013, got %d", w.Main.Pressure)
+	}
+	if w.Visibility == nil || *w.Visibility != 8500 {
+		t.Errorf("expected visibility 8500, got %v", w.Visibility)
+	}
+
+	var missing WeatherResponse
+	if err := json.Unmarshal([]byte(`{"main": {"pressure": 1013}}`), &missing); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if missing.Visibility != nil {
+		t.Errorf("expected nil visibility when absent, got %d", *missing.Visibility)
+	}
+}
+
+func TestFetchWeatherRateLimitedRetriesOnce(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(APIError{Cod: 429, Message: "Too many requests"})
+			return
+		}
+		json.NewEncoder(w).Encode(successResponse())
+	}))
+	defer srv.Close()
+
+	start := time.Now()
+	got, err := newTestClient(srv.URL).FetchWeather(context.Background(), "Almaty")
+	if err != nil {
+		t.Fatalf("expected success after waiting out the rate limit, got %v", err)
+	}
+	if got.Name != "Almaty" {
+		t.Errorf("expected Almaty, got %s", got.Name)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected to wait Retry-After (1s), waited %v", elapsed)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected 2 requests, got %d", n)
+	}
+}
+
+func TestFetchWeatherRateLimitedBeyondDeadline(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(APIError{Cod: 429, Message: "Too many requests"})
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	_, err := newTestClient(srv.URL).FetchWeather(ctx, "Almaty")
+	if err == nil || !strings.Contains(err.Error(), "rate limited, retry after 120s") {
+		t.Fatalf("expected rate limit error with retry hint, got %v", err)
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected wrapped HTTP 429 error, got %v", err)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("expected no retry when Retry-After exceeds the deadline, got %d requests", n)
+	}
+}
+
+func TestConditionEmptyWeather(t *testing.T) {
+	var w WeatherResponse
+	if _, _, ok := w.Condition(); ok {
+		t.Error("expected no condition for empty weather array")
+	}
+
+	w = successResponse()
+	main, desc, ok := w.Condition()
+	if !ok || main != "Clouds" || desc != "overcast clouds" {
+		t.Errorf("unexpected condition %q (%q), ok=%v", main, desc, ok)
+	}
+}
+
+func TestVerboseLogsRedactedURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(successResponse())
+	}))
+	defer srv.Close()
+
+	var log strings.Builder
+	client := newTestClient(srv.URL + "/data/2.5/weather")
+	client.Verbose = &log
+
+	if _, err := client.FetchWeather(context.Background(), "Almaty"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	line := log.String()
+	if !strings.Contains(line, srv.URL+"/data/2.5/weather?") {
+		t.Errorf("expected endpoint in log, got %q", line)
+	}
+	if !strings.Contains(line, "appid=REDACTED") || strings.Contains(line, testAPIKey) {
+		t.Errorf("expected redacted key in log, got %q", line)
+	}
+	if !strings.Contains(line, "q=Almaty") || !strings.HasSuffix(line, "-> 200 OK\n") {
+		t.Errorf("expected query and status in log, got %q", line)
+	}
+}
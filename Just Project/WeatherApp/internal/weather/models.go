@@ -1,20 +1,37 @@
 package weather
 
+import (
+	"fmt"
+	"time"
+)
+
 // WeatherResponse represents the successful JSON response from OpenWeatherMap API.
 type WeatherResponse struct {
-	Name string `json:"name"`
-	Sys  struct {
+	Name  string `json:"name"`
+	Coord struct {
+		Lon float64 `json:"lon"`
+		Lat float64 `json:"lat"`
+	} `json:"coord"`
+	Sys struct {
 		Country string `json:"country"`
+		Sunrise int64  `json:"sunrise"` // Unix time, UTC
+		Sunset  int64  `json:"sunset"`  // Unix time, UTC
 	} `json:"sys"`
-	Main struct {
+	Timezone int `json:"timezone"` // shift from UTC in seconds
+	Main     struct {
 		Temp      float64 `json:"temp"`
 		FeelsLike float64 `json:"feels_like"`
 		Humidity  int     `json:"humidity"`
 		TempMin   float64 `json:"temp_min"`
 		TempMax   float64 `json:"temp_max"`
+		Pressure  int     `json:"pressure"` // hPa
 	} `json:"main"`
-	Wind struct {
+	// Visibility is in meters (the API caps it at 10 km); nil when the
+	// response doesn't include it.
+	Visibility *int `json:"visibility"`
+	Wind       struct {
 		Speed float64 `json:"speed"`
+		Deg   int     `json:"deg"` // meteorological bearing the wind blows from
 	} `json:"wind"`
 	Weather []struct {
 		Main        string `json:"main"`
@@ -22,8 +39,89 @@ type WeatherResponse struct {
 	} `json:"weather"`
 }
 
+// Condition returns the primary weather condition group (e.g. "Clouds")
+// and its description, and false if the response lists no conditions.
+func (w *WeatherResponse) Condition() (main, description string, ok bool) {
+	if len(w.Weather) == 0 {
+		return "", "", false
+	}
+	return w.Weather[0].Main, w.Weather[0].Description, true
+}
+
+// Location returns a fixed time zone for the city's UTC offset.
+func (w *WeatherResponse) Location() *time.Location {
+	return time.FixedZone("", w.Timezone)
+}
+
+// LocalTime returns now shifted to the city's UTC offset. Offsets west of
+// Greenwich are negative.
+func (w *WeatherResponse) LocalTime(now time.Time) time.Time {
+	return now.UTC().In(w.Location())
+}
+
+// SunriseLocal returns the sunrise time in the city's local time, and false
+// if the response has no sunrise (e.g. polar day or night).
+func (w *WeatherResponse) SunriseLocal() (time.Time, bool) {
+	return w.localUnix(w.Sys.Sunrise)
+}
+
+// SunsetLocal returns the sunset time in the city's local time, and false
+// if the response has no sunset.
+func (w *WeatherResponse) SunsetLocal() (time.Time, bool) {
+	return w.localUnix(w.Sys.Sunset)
+}
+
+func (w *WeatherResponse) localUnix(sec int64) (time.Time, bool) {
+	if sec == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(sec, 0).In(w.Location()), true
+}
+
+// AirQualityResponse represents the JSON response of the air pollution API.
+type AirQualityResponse struct {
+	List []struct {
+		Dt   int64 `json:"dt"`
+		Main struct {
+			AQI int `json:"aqi"` // 1 = Good, 2 = Fair, 3 = Moderate, 4 = Poor, 5 = Very Poor
+		} `json:"main"`
+		Components AirComponents `json:"components"`
+	} `json:"list"`
+}
+
+// UVResponse represents the JSON response of the UV index API.
+type UVResponse struct {
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Date    int64   `json:"date"` // Unix time of the reading
+	UVIndex float64 `json:"value"`
+}
+
+// AirComponents holds pollutant concentrations in μg/m³.
+type AirComponents struct {
+	CO   float64 `json:"co"`
+	NO   float64 `json:"no"`
+	NO2  float64 `json:"no2"`
+	O3   float64 `json:"o3"`
+	SO2  float64 `json:"so2"`
+	PM25 float64 `json:"pm2_5"`
+	PM10 float64 `json:"pm10"`
+	NH3  float64 `json:"nh3"`
+}
+
 // APIError represents an error response from OpenWeatherMap API.
 type APIError struct {
 	Cod     any    `json:"cod"` // API returns cod as int or string depending on context
 	Message string `json:"message"`
 }
+
+// HTTPError is returned when the API answers with a non-200 status.
+type HTTPError struct {
+	StatusCode int
+	Message    string        // from the API error body
+	RetryAfter time.Duration // from the Retry-After header, if any
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("API error (HTTP %d): %s", e.StatusCode, e.Message)
+}
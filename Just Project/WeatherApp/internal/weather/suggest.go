@@ -0,0 +1,72 @@
+package weather
+
+import (
+	_ "embed"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed cities.txt
+var citiesFile string
+
+// knownCities is the embedded list of common city names.
+var knownCities = parseCities(citiesFile)
+
+func parseCities(s string) []string {
+	var cities []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		cities = append(cities, line)
+	}
+	return cities
+}
+
+// suggestCity returns the known city closest to name by edit distance,
+// ignoring case and any ",GB"-style region suffix. It returns false when
+// nothing is within a third of the name's length (at least one edit), or
+// when name already matches a known city exactly.
+func suggestCity(name string) (string, bool) {
+	name, _, _ = strings.Cut(name, ",")
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", false
+	}
+
+	maxDist := max(1, utf8.RuneCountInString(name)/3)
+	best, bestDist := "", maxDist+1
+	for _, c := range knownCities {
+		if d := levenshtein(name, strings.ToLower(c)); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" || bestDist == 0 {
+		return "", false
+	}
+	return best, true
+}
+
+// levenshtein returns the number of single-rune insertions, deletions and
+// substitutions needed to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,73 @@
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchWeatherNotFoundSuggestsCity(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(APIError{Cod: "404", Message: "city not found"})
+	}))
+	defer srv.Close()
+
+	_, err := newTestClient(srv.URL).FetchWeather(context.Background(), "Almatu")
+	if err == nil {
+		t.Fatal("expected error for 404 response, got nil")
+	}
+
+	expected := `API error (HTTP 404): city not found; did you mean "Almaty"?`
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err.Error())
+	}
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected wrapped *HTTPError with 404, got %v", err)
+	}
+}
+
+func TestSuggestCity(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"Almatu", "Almaty", true},
+		{"almaty", "", false}, // exact match, nothing to suggest
+		{"Astna", "Astana", true},
+		{"Lodnon,GB", "London", true},
+		{"Shimkent", "Shymkent", true},
+		{"Xq", "", false},
+		{"Nowhere", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := suggestCity(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("suggestCity(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"almaty", "almaty", 0},
+		{"алматы", "алмата", 1},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+)
+
+const uvIndexURL = "https://api.openweathermap.org/data/2.5/uvi"
+
+// FetchUVIndex requests the current UV index at the given latitude and
+// longitude.
+func (c *Client) FetchUVIndex(ctx context.Context, lat, lon float64) (*UVResponse, error) {
+	u, err := url.Parse(c.uvURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse uv index url: %w", err)
+	}
+
+	q := u.Query()
+	q.Set("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	q.Set("lon", strconv.FormatFloat(lon, 'f', -1, 64))
+	q.Set("appid", c.apiKey)
+	u.RawQuery = q.Encode()
+
+	var uv UVResponse
+	if err := c.get(ctx, u.String(), &uv); err != nil {
+		return nil, err
+	}
+	return &uv, nil
+}
+
+// UVLabel names the exposure risk of a UV index value on the WHO scale.
+func UVLabel(uvi float64) string {
+	switch r := math.Round(uvi); {
+	case r < 3:
+		return "Low"
+	case r < 6:
+		return "Moderate"
+	case r < 8:
+		return "High"
+	case r < 11:
+		return "Very High"
+	default:
+		return "Extreme"
+	}
+}
@@ -0,0 +1,62 @@
+package weather
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const uvPayload = `{"lat": 43.26, "lon": 76.93, "date_iso": "2024-06-21T12:00:00Z", "date": 1718971200, "value": 7.35}`
+
+func TestFetchUVIndex(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("lat") != "43.26" || q.Get("lon") != "76.93" {
+			t.Errorf("unexpected coords lat=%s lon=%s", q.Get("lat"), q.Get("lon"))
+		}
+		if got := q.Get("appid"); got != testAPIKey {
+			t.Errorf("expected appid=%s, got %s", testAPIKey, got)
+		}
+		w.Write([]byte(uvPayload))
+	}))
+	defer srv.Close()
+
+	client := newTestClient(srv.URL)
+	client.uvURL = srv.URL
+
+	got, err := client.FetchUVIndex(context.Background(), 43.26, 76.93)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.UVIndex != 7.35 {
+		t.Errorf("expected UV index 7.35, got %v", got.UVIndex)
+	}
+	if got.Date != 1718971200 {
+		t.Errorf("expected date 1718971200, got %d", got.Date)
+	}
+	if label := UVLabel(got.UVIndex); label != "High" {
+		t.Errorf("expected label High, got %s", label)
+	}
+}
+
+func TestUVLabel(t *testing.T) {
+	tests := []struct {
+		uvi  float64
+		want string
+	}{
+		{0, "Low"},
+		{2.4, "Low"},
+		{2.5, "Moderate"},
+		{5.4, "Moderate"},
+		{6, "High"},
+		{7.9, "Very High"},
+		{10.4, "Very High"},
+		{11, "Extreme"},
+	}
+	for _, tt := range tests {
+		if got := UVLabel(tt.uvi); got != tt.want {
+			t.Errorf("UVLabel(%v) = %q, want %q", tt.uvi, got, tt.want)
+		}
+	}
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
 	"time"
@@ -20,12 +24,41 @@ type Config struct {
 	FilePath   string        // путь к файлу с URL
 	MaxWorkers int           // максимум одновременных запросов
 	Timeout    time.Duration // таймаут HTTP-запроса
+	Format     string        // формат вывода: table, json или csv
+	Retries    int           // число повторов после временной ошибки
+	Backoff    time.Duration // пауза перед первым повтором
+	Robots     bool          // соблюдать robots.txt
+	QPS        float64       // макс. запросов в секунду к одному хосту (0 — без ограничения)
+	Ordered    bool          // выводить результаты в порядке файла
+	UserAgent  string        // свой User-Agent ("" — по умолчанию)
+	Headers    headerFlags   // дополнительные заголовки запросов
+}
+
+// headerFlags — значение повторяемого флага -H "Name: value".
+type headerFlags map[string]string
+
+func (h headerFlags) String() string {
+	parts := make([]string, 0, len(h))
+	for k, v := range h {
+		parts = append(parts, k+": "+v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (h headerFlags) Set(s string) error {
+	name, value, ok := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return fmt.Errorf("header must look like \"Name: value\", got %q", s)
+	}
+	h[name] = strings.TrimSpace(value)
+	return nil
 }
 
 // ParseFlags разбирает аргументы командной строки через отдельный FlagSet
 // (удобно для тестирования — не затрагивает глобальный flag.CommandLine).
 func ParseFlags(fs *flag.FlagSet, args []string) Config {
-	var cfg Config
+	cfg := Config{Headers: headerFlags{}}
 	fs.StringVar(&cfg.FilePath, "file", "", "Path to text file with URLs (one per line)")
 	fs.StringVar(&cfg.FilePath, "f", "", "Path to text file with URLs (shorthand)")
 	fs.IntVar(&cfg.MaxWorkers, "workers", 5, "Max concurrent HTTP requests")
@@ -34,6 +67,14 @@ func ParseFlags(fs *flag.FlagSet, args []string) Config {
 	var timeoutSec int
 	fs.IntVar(&timeoutSec, "timeout", 10, "HTTP request timeout in seconds")
 	fs.IntVar(&timeoutSec, "t", 10, "HTTP timeout in seconds (shorthand)")
+	fs.StringVar(&cfg.Format, "format", FormatTable, "Output format: table, json or csv")
+	fs.IntVar(&cfg.Retries, "retries", 2, "Retries after network errors and 5xx responses")
+	fs.DurationVar(&cfg.Backoff, "backoff", 500*time.Millisecond, "Delay before the first retry (doubles each time)")
+	fs.BoolVar(&cfg.Robots, "robots", false, "Skip URLs disallowed by the host's robots.txt")
+	fs.Float64Var(&cfg.QPS, "qps", 0, "Max requests per second to a single host (0 = unlimited)")
+	fs.BoolVar(&cfg.Ordered, "preserve-order", false, "Print results in the same order as the URL file")
+	fs.StringVar(&cfg.UserAgent, "user-agent", "", "User-Agent header (default GoWebScraper/1.0)")
+	fs.Var(cfg.Headers, "H", "Extra request header \"Name: value\" (repeatable)")
 
 	_ = fs.Parse(args)
 
@@ -46,7 +87,13 @@ func ParseFlags(fs *flag.FlagSet, args []string) Config {
 // RunInteractive запрашивает параметры через stdin.
 func RunInteractive(r io.Reader, w io.Writer) Config {
 	scanner := bufio.NewScanner(r)
-	cfg := Config{MaxWorkers: 5, Timeout: 10 * time.Second}
+	cfg := Config{
+		MaxWorkers: 5,
+		Timeout:    10 * time.Second,
+		Format:     FormatTable,
+		Retries:    2,
+		Backoff:    500 * time.Millisecond,
+	}
 
 	fmt.Fprintln(w, "=== Web Scraper (interactive mode) ===")
 	fmt.Fprintln(w)
@@ -111,27 +158,139 @@ func ReadURLs(r io.Reader) ([]string, error) {
 
 // ---------- Вывод результатов ----------
 
+// Форматы вывода для флага -format.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// checkFormat проверяет, что формат вывода поддерживается.
+func checkFormat(format string) error {
+	switch format {
+	case FormatTable, FormatJSON, FormatCSV:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q (want table, json or csv)", format)
+}
+
+// jsonResult — представление Result в JSON-выводе; Error равен null при успехе.
+type jsonResult struct {
+	URL           string  `json:"url"`
+	StatusCode    int     `json:"status_code"`
+	ContentLength int64   `json:"content_length"`
+	Title         string  `json:"title"`
+	Description   string  `json:"description"`
+	H1            string  `json:"h1"`
+	Error         *string `json:"error"`
+}
+
+// WriteResults выводит результаты в формате format: table (как PrintResults),
+// json (массив объектов с полями url, status_code, content_length, title,
+// description, h1, error) или csv (с заголовком из тех же полей).
+func WriteResults(w io.Writer, results []scraper.Result, format string) error {
+	return StreamResults(w, sliceChan(results), format)
+}
+
+// StreamResults выводит результаты из канала по мере поступления, пока канал
+// не закроется. Форматы — как в WriteResults; table и csv печатают каждую
+// строку сразу, json (один массив) пишется после закрытия канала.
+func StreamResults(w io.Writer, results <-chan scraper.Result, format string) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatJSON:
+		out := []jsonResult{}
+		for r := range results {
+			jr := jsonResult{
+				URL:           r.URL,
+				StatusCode:    r.StatusCode,
+				ContentLength: r.ContentLength,
+				Title:         r.Title,
+				Description:   r.Description,
+				H1:            r.H1,
+			}
+			if r.Err != nil {
+				msg := r.Err.Error()
+				jr.Error = &msg
+			}
+			out = append(out, jr)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"url", "status_code", "content_length", "title", "description", "h1", "error"})
+		for r := range results {
+			var errMsg string
+			if r.Err != nil {
+				errMsg = r.Err.Error()
+			}
+			cw.Write([]string{
+				r.URL,
+				strconv.Itoa(r.StatusCode),
+				strconv.FormatInt(r.ContentLength, 10),
+				r.Title,
+				r.Description,
+				r.H1,
+				errMsg,
+			})
+			cw.Flush() // строка уходит в w сразу, не дожидаясь остальных
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	printTable(w, results)
+	return nil
+}
+
 // PrintResults форматирует и печатает результаты скрапинга.
+// Колонка STATUS показывает HTTP-код ответа или "-", если ответа не было.
 func PrintResults(w io.Writer, results []scraper.Result) {
-	fmt.Fprintln(w, strings.Repeat("─", 60))
-	fmt.Fprintf(w, "  %-40s  %s\n", "URL", "TITLE / ERROR")
-	fmt.Fprintln(w, strings.Repeat("─", 60))
+	printTable(w, sliceChan(results))
+}
+
+// printTable печатает таблицу PrintResults, строку за строкой по мере
+// поступления результатов из канала.
+func printTable(w io.Writer, results <-chan scraper.Result) {
+	fmt.Fprintln(w, strings.Repeat("─", 70))
+	fmt.Fprintf(w, "  %-40s  %-6s  %s\n", "URL", "STATUS", "TITLE / ERROR")
+	fmt.Fprintln(w, strings.Repeat("─", 70))
 
 	var ok, fail int
-	for _, r := range results {
+	for r := range results {
+		status := "-"
+		if r.StatusCode != 0 {
+			status = strconv.Itoa(r.StatusCode)
+		}
 		if r.Err != nil {
-			fmt.Fprintf(w, "  %-40s  [ERROR] %v\n", truncate(r.URL, 40), r.Err)
+			fmt.Fprintf(w, "  %-40s  %-6s  [ERROR] %v\n", truncate(r.URL, 40), status, r.Err)
 			fail++
 		} else {
-			fmt.Fprintf(w, "  %-40s  %s\n", truncate(r.URL, 40), r.Title)
+			fmt.Fprintf(w, "  %-40s  %-6s  %s\n", truncate(r.URL, 40), status, r.Title)
 			ok++
 		}
 	}
 
-	fmt.Fprintln(w, strings.Repeat("─", 60))
+	fmt.Fprintln(w, strings.Repeat("─", 70))
 	fmt.Fprintf(w, "  Done: %d success, %d failed, %d total\n", ok, fail, ok+fail)
 }
 
+// sliceChan возвращает закрытый канал с элементами results.
+func sliceChan(results []scraper.Result) <-chan scraper.Result {
+	ch := make(chan scraper.Result, len(results))
+	for _, r := range results {
+		ch <- r
+	}
+	close(ch)
+	return ch
+}
+
 // truncate обрезает строку до maxLen символов, добавляя "…" при обрезке.
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -157,19 +316,52 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := checkFormat(cfg.Format); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
 	urls, err := LoadURLs(cfg.FilePath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("Scraping %d URLs (workers=%d, timeout=%s)…\n\n",
+	// В машиночитаемых форматах stdout содержит только данные,
+	// поэтому служебные сообщения уходят в stderr.
+	status := io.Writer(os.Stdout)
+	if cfg.Format != FormatTable {
+		status = os.Stderr
+	}
+	fmt.Fprintf(status, "Scraping %d URLs (workers=%d, timeout=%s)…\n\n",
 		len(urls), cfg.MaxWorkers, cfg.Timeout)
 
-	results := scraper.Run(urls, scraper.Config{
-		MaxWorkers: cfg.MaxWorkers,
-		Timeout:    cfg.Timeout,
-	})
+	// Ctrl+C прерывает текущие запросы; уже собранные результаты печатаются,
+	// остальные URL получают ошибку context canceled.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	scfg := scraper.Config{
+		MaxWorkers:    cfg.MaxWorkers,
+		Timeout:       cfg.Timeout,
+		MaxRetries:    cfg.Retries,
+		RetryBackoff:  cfg.Backoff,
+		RespectRobots: cfg.Robots,
+		PerHostQPS:    cfg.QPS,
+		PreserveOrder: cfg.Ordered,
+		UserAgent:     cfg.UserAgent,
+		Headers:       cfg.Headers,
+	}
 
-	PrintResults(os.Stdout, results)
+	// Без --preserve-order результаты печатаются по мере готовности;
+	// с ним — после завершения всех запросов, в порядке файла.
+	if cfg.Ordered {
+		err = WriteResults(os.Stdout, scraper.RunContext(ctx, urls, scfg), cfg.Format)
+	} else {
+		err = StreamResults(os.Stdout, scraper.RunStreamContext(ctx, urls, scfg), cfg.Format)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	"webscraper/scraper"
+)
+
+// sampleResults — полный результат, заголовок с запятой и кавычками,
+// ответ 404 и запрос без ответа.
+var sampleResults = []scraper.Result{
+	{URL: "https://go.dev", StatusCode: 200, ContentLength: 6120, Title: "The Go Programming Language", Description: "Build simple, secure software", H1: "Go"},
+	{URL: "https://example.com", StatusCode: 200, ContentLength: 1256, Title: `Hello, "World"`},
+	{URL: "https://bad.example", StatusCode: 404, ContentLength: 19, Err: errors.New("HTTP 404")},
+	{URL: "https://down.example", Err: errors.New("request failed: connection refused")},
+}
+
+func TestWriteResultsJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, sampleResults, FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got) != len(sampleResults) {
+		t.Fatalf("expected %d objects, got %d", len(sampleResults), len(got))
+	}
+	if got[0]["url"] != "https://go.dev" || got[0]["title"] != "The Go Programming Language" ||
+		got[0]["description"] != "Build simple, secure software" || got[0]["h1"] != "Go" {
+		t.Errorf("unexpected first object: %v", got[0])
+	}
+	if v, ok := got[0]["error"]; !ok || v != nil {
+		t.Errorf("error = %v (present=%v), want null", v, ok)
+	}
+	if got[2]["error"] != "HTTP 404" || got[2]["status_code"] != float64(404) {
+		t.Errorf("unexpected failed object: %v", got[2])
+	}
+	if got[0]["status_code"] != float64(200) || got[0]["content_length"] != float64(6120) {
+		t.Errorf("unexpected status fields: %v", got[0])
+	}
+}
+
+func TestWriteResultsJSONEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, nil, FormatJSON); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("output = %q, want []", got)
+	}
+}
+
+func TestWriteResultsCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, sampleResults, FormatCSV); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"Hello, ""World"""`) {
+		t.Errorf("title with comma is not quoted:\n%s", buf.String())
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	want := [][]string{
+		{"url", "status_code", "content_length", "title", "description", "h1", "error"},
+		{"https://go.dev", "200", "6120", "The Go Programming Language", "Build simple, secure software", "Go", ""},
+		{"https://example.com", "200", "1256", `Hello, "World"`, "", "", ""},
+		{"https://bad.example", "404", "19", "", "", "", "HTTP 404"},
+		{"https://down.example", "0", "0", "", "", "", "request failed: connection refused"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d", len(want), len(rows))
+	}
+	for i := range want {
+		if strings.Join(rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, rows[i], want[i])
+		}
+	}
+}
+
+func TestWriteResultsTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, sampleResults, FormatTable); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "404     [ERROR] HTTP 404") || !strings.Contains(out, "-       [ERROR] request failed") ||
+		!strings.Contains(out, "200     The Go Programming Language") || !strings.Contains(out, "Done: 2 success, 2 failed, 4 total") {
+		t.Errorf("unexpected table output:\n%s", out)
+	}
+}
+
+func TestWriteResultsUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResults(&buf, sampleResults, "xml"); err == nil {
+		t.Fatal("expected error for unknown format, got nil")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no output, got %q", buf.String())
+	}
+}
+
+func TestParseFlagsFormat(t *testing.T) {
+	cfg := ParseFlags(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-f", "urls.txt"})
+	if cfg.Format != FormatTable {
+		t.Errorf("default format = %q, want %q", cfg.Format, FormatTable)
+	}
+
+	cfg = ParseFlags(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-f", "urls.txt", "-format", "json"})
+	if cfg.Format != FormatJSON {
+		t.Errorf("format = %q, want %q", cfg.Format, FormatJSON)
+	}
+}
+
+func TestParseFlagsHeaders(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg := ParseFlags(fs, []string{
+		"-f", "urls.txt",
+		"-user-agent", "AuditBot/2.0",
+		"-H", "Accept-Language: ru-RU, en;q=0.8",
+		"-H", "X-Token:secret",
+	})
+
+	if cfg.UserAgent != "AuditBot/2.0" {
+		t.Errorf("user agent = %q, want %q", cfg.UserAgent, "AuditBot/2.0")
+	}
+	if len(cfg.Headers) != 2 || cfg.Headers["Accept-Language"] != "ru-RU, en;q=0.8" || cfg.Headers["X-Token"] != "secret" {
+		t.Errorf("unexpected headers: %v", cfg.Headers)
+	}
+
+	if err := (headerFlags{}).Set("no colon"); err == nil {
+		t.Error("expected error for header without colon")
+	}
+}
+
+func TestStreamResultsMatchesWriteResults(t *testing.T) {
+	for _, format := range []string{FormatTable, FormatJSON, FormatCSV} {
+		var want, got bytes.Buffer
+		if err := WriteResults(&want, sampleResults, format); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+
+		ch := make(chan scraper.Result)
+		go func() {
+			for _, r := range sampleResults {
+				ch <- r
+			}
+			close(ch)
+		}()
+		if err := StreamResults(&got, ch, format); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+
+		if got.String() != want.String() {
+			t.Errorf("%s: streamed output differs:\n%s\nwant:\n%s", format, got.String(), want.String())
+		}
+	}
+}
+
+// notifyWriter сообщает в wrote о каждой записи.
+type notifyWriter struct {
+	bytes.Buffer
+	wrote chan string
+}
+
+func (w *notifyWriter) Write(p []byte) (int, error) {
+	w.wrote <- string(p)
+	return w.Buffer.Write(p)
+}
+
+func TestStreamResultsCSVWritesEachRow(t *testing.T) {
+	w := &notifyWriter{wrote: make(chan string, 10)}
+	ch := make(chan scraper.Result)
+	done := make(chan error)
+	go func() { done <- StreamResults(w, ch, FormatCSV) }()
+
+	ch <- sampleResults[0]
+	// Строка должна появиться до того, как канал закрыт.
+	var out string
+	for !strings.Contains(out, "https://go.dev") {
+		select {
+		case p := <-w.wrote:
+			out += p
+		case <-time.After(time.Second):
+			t.Fatalf("row was not written before the channel closed, got %q", out)
+		}
+	}
+
+	close(ch)
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
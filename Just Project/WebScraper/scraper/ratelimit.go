@@ -0,0 +1,57 @@
+package scraper
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// hostLimiter ограничивает частоту запросов к каждому хосту отдельно.
+// Для хоста хранится время, раньше которого следующий запрос начинать
+// нельзя; Wait резервирует ближайший свободный момент и спит до него.
+// Это token bucket ёмкостью в один токен. Безопасен для конкурентного
+// использования.
+type hostLimiter struct {
+	interval time.Duration // минимальный промежуток между запросами к хосту
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// newHostLimiter создаёт ограничитель на qps запросов в секунду к хосту,
+// или nil, если ограничения нет (qps <= 0).
+func newHostLimiter(qps float64) *hostLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	return &hostLimiter{
+		interval: time.Duration(float64(time.Second) / qps),
+		next:     make(map[string]time.Time),
+	}
+}
+
+// Wait блокируется, пока к хосту rawURL снова можно обращаться, или до
+// отмены ctx (тогда возвращает ctx.Err()). Для nil-ограничителя
+// возвращается сразу.
+func (l *hostLimiter) Wait(ctx context.Context, rawURL string) error {
+	if l == nil {
+		return ctx.Err()
+	}
+
+	host := rawURL
+	if u, err := url.Parse(normalizeURL(rawURL)); err == nil && u.Host != "" {
+		host = u.Host
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next[host] = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	return sleepContext(ctx, time.Until(slot))
+}
@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// ---------- Тесты ограничения частоты ----------
+
+func TestHostLimiterDisabled(t *testing.T) {
+	if l := newHostLimiter(0); l != nil {
+		t.Fatalf("expected nil limiter for qps=0, got %+v", l)
+	}
+	var l *hostLimiter
+	start := time.Now()
+	l.Wait(context.Background(), "https://example.com")
+	if time.Since(start) > 10*time.Millisecond {
+		t.Error("nil limiter must not block")
+	}
+}
+
+func TestHostLimiterPerHost(t *testing.T) {
+	l := newHostLimiter(20) // 50ms между запросами к хосту
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		l.Wait(context.Background(), "https://a.example/page")
+		l.Wait(context.Background(), "b.example/other") // другой хост, схема подставится
+	}
+	// Третий запрос к каждому хосту — не раньше 2*50ms.
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("elapsed = %v, want at least 100ms", elapsed)
+	}
+	// Хосты не мешают друг другу: у каждого свой слот. Проверяем по ключам,
+	// а не по времени, чтобы тест не зависел от загрузки машины.
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, host := range []string{"a.example", "b.example"} {
+		if _, ok := l.next[host]; !ok {
+			t.Errorf("no separate slot for %s, got %v", host, l.next)
+		}
+	}
+}
+
+func TestRunPerHostQPS(t *testing.T) {
+	srv := newTestServer(testPageTitle)
+	defer srv.Close()
+
+	var urls []string
+	for i := 0; i < 10; i++ {
+		urls = append(urls, srv.URL)
+	}
+
+	// 20 запросов/с => 10 запросов займут не меньше 9 * 50ms.
+	cfg := Config{MaxWorkers: 10, Timeout: 2 * time.Second, PerHostQPS: 20}
+	start := time.Now()
+	results := Run(urls, cfg)
+	elapsed := time.Since(start)
+
+	if len(results) != len(urls) {
+		t.Fatalf("expected %d results, got %d", len(urls), len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("error for %s: %v", r.URL, r.Err)
+		}
+	}
+	if want := 450 * time.Millisecond; elapsed < want {
+		t.Errorf("elapsed = %v, want at least %v for 10 requests at 20 QPS", elapsed, want)
+	}
+	if elapsed > 2*time.Second {
+		t.Errorf("elapsed = %v, limiter is too slow", elapsed)
+	}
+}
@@ -0,0 +1,184 @@
+package scraper
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrDisallowed возвращается в Result.Err, если robots.txt запрещает адрес.
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// robotsRule — одна строка Allow/Disallow из robots.txt.
+type robotsRule struct {
+	allow   bool
+	length  int            // длина исходного шаблона: более длинный шаблон важнее
+	pattern *regexp.Regexp // шаблон пути с поддержкой * и завершающего $
+}
+
+// robotsRules — правила, относящиеся к нашему User-Agent.
+// Пустой набор разрешает всё.
+type robotsRules []robotsRule
+
+// allowed сообщает, можно ли запрашивать путь (с query-строкой).
+// Побеждает самое длинное совпавшее правило; при равной длине — Allow.
+func (rules robotsRules) allowed(path string) bool {
+	best := -1
+	allow := true
+	for _, r := range rules {
+		if !r.pattern.MatchString(path) {
+			continue
+		}
+		if r.length > best || (r.length == best && r.allow) {
+			best, allow = r.length, r.allow
+		}
+	}
+	return allow
+}
+
+// parseRobots разбирает robots.txt и оставляет правила групп, чей
+// User-agent входит в имя робота agent (без учёта регистра); если таких
+// групп нет — правила группы "*".
+func parseRobots(r io.Reader, agent string) robotsRules {
+	agent = strings.ToLower(agent)
+	var (
+		own, wildcard  robotsRules
+		hasOwn         bool
+		forOwn, forAny bool // текущая группа относится к нам / к "*"
+		inAgents       bool // идут подряд строки User-agent
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				forOwn, forAny = false, false
+				inAgents = true
+			}
+			name := strings.ToLower(value)
+			switch {
+			case name == "*":
+				forAny = true
+			case name != "" && strings.Contains(agent, name):
+				forOwn, hasOwn = true, true
+			}
+
+		case "allow", "disallow":
+			inAgents = false
+			if value == "" { // пустой Disallow ничего не запрещает
+				continue
+			}
+			rule := robotsRule{allow: key == "allow", length: len(value), pattern: compileRobotsPattern(value)}
+			if forOwn {
+				own = append(own, rule)
+			}
+			if forAny {
+				wildcard = append(wildcard, rule)
+			}
+
+		default:
+			inAgents = false
+		}
+	}
+
+	if hasOwn {
+		return own
+	}
+	return wildcard
+}
+
+// compileRobotsPattern превращает шаблон пути robots.txt в регулярное
+// выражение: * — любая последовательность символов, $ в конце — конец пути.
+func compileRobotsPattern(p string) *regexp.Regexp {
+	anchored := strings.HasSuffix(p, "$")
+	p = strings.TrimSuffix(p, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(p), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// ---------- Кэш robots.txt ----------
+
+// robotsEntry — правила одного хоста; once гарантирует, что robots.txt
+// загружается один раз, даже если хост одновременно нужен нескольким воркерам.
+type robotsEntry struct {
+	once  sync.Once
+	rules robotsRules
+}
+
+// robotsCache хранит разобранные robots.txt по схеме и хосту.
+// Безопасен для конкурентного использования.
+type robotsCache struct {
+	client *http.Client
+	cfg    Config // заголовки и User-Agent запросов robots.txt
+
+	mu    sync.Mutex
+	hosts map[string]*robotsEntry
+}
+
+func newRobotsCache(client *http.Client, cfg Config) *robotsCache {
+	return &robotsCache{client: client, cfg: cfg, hosts: make(map[string]*robotsEntry)}
+}
+
+// Allowed сообщает, разрешён ли адрес правилами robots.txt его хоста.
+// Некорректный URL считается разрешённым — ошибку покажет сам запрос.
+func (c *robotsCache) Allowed(ctx context.Context, rawURL string) bool {
+	u, err := url.Parse(normalizeURL(rawURL))
+	if err != nil || u.Host == "" {
+		return true
+	}
+
+	key := u.Scheme + "://" + u.Host
+	c.mu.Lock()
+	entry, ok := c.hosts[key]
+	if !ok {
+		entry = &robotsEntry{}
+		c.hosts[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() { entry.rules = c.fetch(ctx, key) })
+	return entry.rules.allowed(u.RequestURI())
+}
+
+// fetch загружает и разбирает robots.txt хоста с тем же клиентом (таймаутом),
+// заголовками и User-Agent, что и обычные запросы. Группа правил выбирается
+// по имени из User-Agent (часть до "/"). Если файл недоступен (ошибка сети,
+// код не 200), ограничений нет.
+func (c *robotsCache) fetch(ctx context.Context, origin string) robotsRules {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil
+	}
+	setHeaders(req, c.cfg)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	// robots.txt больше 500 КБ дальше не читаем — как и поисковые роботы.
+	agent, _, _ := strings.Cut(req.Header.Get("User-Agent"), "/")
+	return parseRobots(io.LimitReader(resp.Body, 500<<10), strings.TrimSpace(agent))
+}
@@ -0,0 +1,154 @@
+package scraper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ---------- Тесты разбора robots.txt ----------
+
+func TestParseRobots(t *testing.T) {
+	tests := []struct {
+		name   string
+		robots string
+		path   string
+		want   bool
+	}{
+		{"empty_file", ``, "/anything", true},
+		{"disallow_prefix", "User-agent: *\nDisallow: /private", "/private/page", false},
+		{"other_path_allowed", "User-agent: *\nDisallow: /private", "/public", true},
+		{"empty_disallow", "User-agent: *\nDisallow:", "/private", true},
+		{"disallow_all", "User-agent: *\nDisallow: /", "/", false},
+		{"longer_allow_wins", "User-agent: *\nDisallow: /docs\nAllow: /docs/public", "/docs/public/a", true},
+		{"longer_disallow_wins", "User-agent: *\nAllow: /docs\nDisallow: /docs/secret", "/docs/secret", false},
+		{"wildcard", "User-agent: *\nDisallow: /*.pdf", "/files/report.pdf", false},
+		{"end_anchor", "User-agent: *\nDisallow: /*.pdf$", "/files/report.pdf?dl=1", true},
+		{"query_string", "User-agent: *\nDisallow: /search?q=", "/search?q=go", false},
+		{"comments", "# comment\nUser-agent: * # all\nDisallow: /tmp # temp", "/tmp/x", false},
+		{"own_group_preferred", "User-agent: *\nDisallow: /\n\nUser-agent: GoWebScraper\nDisallow: /admin", "/page", true},
+		{"own_group_rules", "User-agent: *\nDisallow: /\n\nUser-agent: gowebscraper\nDisallow: /admin", "/admin", false},
+		{"other_bot_ignored", "User-agent: Googlebot\nDisallow: /", "/page", true},
+		{"shared_group", "User-agent: Googlebot\nUser-agent: *\nDisallow: /shared", "/shared", false},
+		{"case_insensitive_keys", "USER-AGENT: *\nDISALLOW: /x", "/x", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rules := parseRobots(strings.NewReader(tc.robots), "GoWebScraper")
+			if got := rules.allowed(tc.path); got != tc.want {
+				t.Errorf("allowed(%q) = %v, want %v", tc.path, got, tc.want)
+			}
+		})
+	}
+}
+
+// ---------- Тесты Run с RespectRobots ----------
+
+// newRobotsServer отдаёт robots.txt, запрещающий /private, и страницы
+// для остальных путей; счётчики считают запросы robots.txt и /private.
+func newRobotsServer(robotsHits, privateHits *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/robots.txt":
+			robotsHits.Add(1)
+			if r.Header.Get("User-Agent") != userAgent {
+				http.Error(w, "unexpected user agent", http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		case strings.HasPrefix(r.URL.Path, "/private"):
+			privateHits.Add(1)
+			fmt.Fprint(w, "<title>Private</title>")
+		default:
+			fmt.Fprintf(w, "<title>%s</title>", r.URL.Path)
+		}
+	}))
+}
+
+func TestRunRespectRobots(t *testing.T) {
+	var robotsHits, privateHits atomic.Int32
+	srv := newRobotsServer(&robotsHits, &privateHits)
+	defer srv.Close()
+
+	urls := []string{srv.URL + "/a", srv.URL + "/private/x", srv.URL + "/b", srv.URL + "/private"}
+	cfg := Config{MaxWorkers: 4, Timeout: 2 * time.Second, RespectRobots: true}
+	results := Run(urls, cfg)
+
+	if len(results) != len(urls) {
+		t.Fatalf("expected %d results, got %d", len(urls), len(results))
+	}
+	for _, r := range results {
+		private := strings.Contains(r.URL, "/private")
+		switch {
+		case private && !errors.Is(r.Err, ErrDisallowed):
+			t.Errorf("%s: expected ErrDisallowed, got %v", r.URL, r.Err)
+		case !private && r.Err != nil:
+			t.Errorf("%s: unexpected error: %v", r.URL, r.Err)
+		}
+	}
+	if got := robotsHits.Load(); got != 1 {
+		t.Errorf("robots.txt requests = %d, want 1 (cached per host)", got)
+	}
+	if got := privateHits.Load(); got != 0 {
+		t.Errorf("disallowed pages requested %d times", got)
+	}
+}
+
+func TestRunIgnoresRobotsByDefault(t *testing.T) {
+	var robotsHits, privateHits atomic.Int32
+	srv := newRobotsServer(&robotsHits, &privateHits)
+	defer srv.Close()
+
+	results := Run([]string{srv.URL + "/private"}, Config{MaxWorkers: 1, Timeout: 2 * time.Second})
+
+	if len(results) != 1 {
+		t.Fatalf(errOneResultFmt, len(results))
+	}
+	if results[0].Err != nil {
+		t.Fatalf("unexpected error: %v", results[0].Err)
+	}
+	if robotsHits.Load() != 0 {
+		t.Error("robots.txt must not be fetched when RespectRobots is off")
+	}
+}
+
+func TestRunRobotsCustomAgent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /\n\nUser-agent: AuditBot\nAllow: /\n")
+			return
+		}
+		fmt.Fprint(w, "<title>Allowed</title>")
+	}))
+	defer srv.Close()
+
+	cfg := Config{MaxWorkers: 1, Timeout: 2 * time.Second, RespectRobots: true, UserAgent: "AuditBot/2.0"}
+	results := Run([]string{srv.URL + "/page"}, cfg)
+
+	if results[0].Err != nil {
+		t.Errorf("expected AuditBot group to allow the page, got %v", results[0].Err)
+	}
+}
+
+func TestRunRobotsMissing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "<title>Open</title>")
+	}))
+	defer srv.Close()
+
+	results := Run([]string{srv.URL + "/page"}, Config{MaxWorkers: 1, Timeout: 2 * time.Second, RespectRobots: true})
+
+	if results[0].Err != nil || results[0].Title != "Open" {
+		t.Errorf("expected page to be fetched without robots.txt, got %+v", results[0])
+	}
+}
@@ -1,4 +1,5 @@
-// Package scraper реализует конкурентный сбор HTML-заголовков (<title>) по списку URL.
+// Package scraper реализует конкурентный сбор HTML-заголовков (<title>),
+// meta description и первого <h1> по списку URL.
 //
 // Ключевые примитивы синхронизации:
 //   - sync.WaitGroup  — счётчик активных горутин; main-горутина блокируется
@@ -12,6 +13,7 @@ package scraper
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -26,32 +28,158 @@ import (
 
 // Result описывает результат обработки одного URL.
 type Result struct {
-	URL   string // запрошенный адрес
-	Title string // содержимое <title>, если удалось извлечь
-	Err   error  // ошибка запроса или парсинга (nil при успехе)
+	URL         string // запрошенный адрес
+	Title       string // содержимое <title>, если удалось извлечь
+	Description string // content из <meta name="description">, или ""
+	H1          string // текст первого <h1>, или ""
+
+	// StatusCode и ContentLength заполняются для любого полученного ответа,
+	// в том числе не 200; 0 — запрос не завершился (сеть, таймаут, robots.txt).
+	StatusCode    int   // HTTP-код ответа
+	ContentLength int64 // размер тела: Content-Length или число прочитанных байт
+
+	Err error // ошибка запроса или парсинга (nil при успехе)
+}
+
+// PageInfo — сведения, извлекаемые из HTML-страницы за один проход.
+type PageInfo struct {
+	Title       string
+	Description string
+	H1          string
 }
 
 // Config задаёт параметры скрапера.
 type Config struct {
-	MaxWorkers int           // макс. число одновременных HTTP-запросов (семафор)
-	Timeout    time.Duration // таймаут одного HTTP-запроса
+	MaxWorkers   int           // макс. число одновременных HTTP-запросов (семафор)
+	Timeout      time.Duration // таймаут одного HTTP-запроса
+	MaxRetries   int           // сколько раз повторить запрос после временной ошибки (0 — без повторов)
+	RetryBackoff time.Duration // пауза перед первым повтором; каждая следующая вдвое длиннее
+
+	// RespectRobots включает проверку robots.txt: запрещённые адреса не
+	// запрашиваются, а получают ErrDisallowed.
+	RespectRobots bool
+
+	// PerHostQPS ограничивает число запросов в секунду к одному хосту
+	// (включая повторы); 0 — без ограничения. Семафор MaxWorkers действует
+	// поверх этого ограничения.
+	PerHostQPS float64
+
+	// PreserveOrder возвращает результаты в порядке входных URL:
+	// results[i] соответствует urls[i].
+	PreserveOrder bool
+
+	// UserAgent заменяет User-Agent по умолчанию ("GoWebScraper/1.0").
+	UserAgent string
+	// Headers добавляются к каждому запросу, включая повторы и robots.txt.
+	// User-Agent из Headers используется, только если UserAgent пуст.
+	Headers map[string]string
 }
 
-// DefaultConfig возвращает конфигурацию по умолчанию: 5 воркеров, 10 секунд таймаут.
+// DefaultConfig возвращает конфигурацию по умолчанию: 5 воркеров, 10 секунд таймаут,
+// 2 повтора с паузой от 500 мс.
 func DefaultConfig() Config {
 	return Config{
-		MaxWorkers: 5,
-		Timeout:    10 * time.Second,
+		MaxWorkers:   5,
+		Timeout:      10 * time.Second,
+		MaxRetries:   2,
+		RetryBackoff: 500 * time.Millisecond,
+	}
+}
+
+// userAgent — User-Agent запросов скрапера по умолчанию.
+const userAgent = "GoWebScraper/1.0"
+
+// setHeaders добавляет к запросу cfg.Headers и User-Agent: cfg.UserAgent,
+// User-Agent из cfg.Headers или userAgent по умолчанию.
+func setHeaders(req *http.Request, cfg Config) {
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if cfg.UserAgent != "" {
+		req.Header.Set("User-Agent", cfg.UserAgent)
+	} else if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", userAgent)
 	}
 }
 
+// StatusError — сервер ответил кодом, отличным от 200 OK.
+type StatusError struct {
+	Code int // HTTP-код ответа
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.Code)
+}
+
 // ---------- Публичный API ----------
 
 // Run запускает конкурентный сбор заголовков для переданных URL.
 // Возвращает срез Result (по одному на каждый URL) после обработки всех адресов.
 //
-// Порядок результатов НЕ гарантирован — он зависит от скорости ответов серверов.
+// По умолчанию порядок результатов НЕ гарантирован — он зависит от скорости
+// ответов серверов. С cfg.PreserveOrder результаты идут в порядке urls.
 func Run(urls []string, cfg Config) []Result {
+	return RunContext(context.Background(), urls, cfg)
+}
+
+// RunContext — то же, что Run, но с отменой через ctx. После отмены новые
+// воркеры не запускаются, ожидающие слота семафора или своей очереди к хосту
+// не делают запрос, а текущие запросы прерываются. Такие URL всё равно
+// получают Result, и его Err оборачивает ошибку контекста (errors.Is
+// с context.Canceled или context.DeadlineExceeded).
+func RunContext(ctx context.Context, urls []string, cfg Config) []Result {
+	// ----- Срез результатов по индексам -----
+	// При PreserveOrder каждый воркер пишет в свою ячейку ordered[i]; ячейки
+	// не пересекаются, поэтому мьютекс не нужен, а wg.Wait() гарантирует,
+	// что все записи видны до чтения среза.
+	if cfg.PreserveOrder {
+		ordered := make([]Result, len(urls))
+		wg := launch(ctx, urls, cfg, func(i int, r Result) { ordered[i] = r })
+		wg.Wait()
+		return ordered
+	}
+
+	// ----- Агрегация результатов -----
+	// Читаем из канала до его закрытия. Это происходит в текущей горутине,
+	// поэтому функция Run сама блокируется, пока все результаты не будут собраны.
+	collected := make([]Result, 0, len(urls))
+	for r := range RunStreamContext(ctx, urls, cfg) {
+		collected = append(collected, r)
+	}
+	return collected
+}
+
+// RunStream запускает сбор и сразу возвращает канал, в который результаты
+// приходят по мере готовности (в порядке завершения, cfg.PreserveOrder
+// не учитывается). Канал закрывается, когда обработаны все URL.
+// Вызывающий должен читать канал до закрытия — иначе воркеры заблокируются.
+func RunStream(urls []string, cfg Config) <-chan Result {
+	return RunStreamContext(context.Background(), urls, cfg)
+}
+
+// RunStreamContext — RunStream с отменой через ctx (см. RunContext).
+func RunStreamContext(ctx context.Context, urls []string, cfg Config) <-chan Result {
+	// ----- Канал результатов -----
+	// Буфер на MaxWorkers — воркеры не ждут читателя, пока он успевает.
+	results := make(chan Result, max(cfg.MaxWorkers, 1))
+
+	// ----- Горутина-«закрыватель» -----
+	// Запускает воркеров, ждёт их завершения, затем закрывает канал results,
+	// чтобы читатель (range) корректно завершился. Запуск тоже идёт здесь,
+	// чтобы RunStream возвращал канал сразу.
+	go func() {
+		wg := launch(ctx, urls, cfg, func(_ int, r Result) { results <- r })
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// launch запускает по воркеру на URL и вызывает emit(i, result) для каждого
+// urls[i] — конкурентно, из горутин воркеров. Возвращает WaitGroup, который
+// завершается после последнего emit.
+func launch(ctx context.Context, urls []string, cfg Config, emit func(i int, r Result)) *sync.WaitGroup {
 	if cfg.MaxWorkers < 1 {
 		cfg.MaxWorkers = 1
 	}
@@ -67,9 +195,16 @@ func Run(urls []string, cfg Config) []Result {
 	// если все слоты заняты, и продолжает только когда один из слотов освободится.
 	sem := make(chan struct{}, cfg.MaxWorkers)
 
-	// ----- Канал результатов -----
-	// Небуферизованный (или маленький буфер) — воркеры пишут, агрегатор читает.
-	results := make(chan Result, len(urls))
+	// ----- Кэш robots.txt -----
+	// Общий для всех воркеров; каждый хост запрашивается один раз.
+	var robots *robotsCache
+	if cfg.RespectRobots {
+		robots = newRobotsCache(client, cfg)
+	}
+
+	// ----- Ограничение частоты по хостам -----
+	// nil, если PerHostQPS не задан.
+	limiter := newHostLimiter(cfg.PerHostQPS)
 
 	// ----- WaitGroup -----
 	// Счётчик увеличивается на 1 перед запуском каждой горутины
@@ -77,96 +212,265 @@ func Run(urls []string, cfg Config) []Result {
 	var wg sync.WaitGroup
 
 	// Запускаем по одной горутине на URL.
-	for _, u := range urls {
+	for i, u := range urls {
+		// Контекст отменён — горутину не запускаем, сразу отдаём ошибку.
+		if err := ctx.Err(); err != nil {
+			emit(i, Result{URL: u, Err: err})
+			continue
+		}
+
 		wg.Add(1) // +1 ДО запуска горутины — гарантирует, что Wait не завершится раньше времени.
 
-		go func(rawURL string) {
+		go func(i int, rawURL string) {
 			defer wg.Done() // при любом исходе уменьшаем счётчик
 
-			// Захватываем слот семафора (блокирует, если все MaxWorkers слотов заняты).
-			sem <- struct{}{}
-			// Освобождаем слот после завершения работы.
-			defer func() { <-sem }()
+			var page fetchResult
+			var err error
+
+			// Захватываем слот семафора (блокирует, если все MaxWorkers слотов заняты),
+			// если только контекст не отменят раньше.
+			select {
+			case sem <- struct{}{}:
+				if robots != nil && !robots.Allowed(ctx, rawURL) {
+					err = ErrDisallowed
+				} else {
+					page, err = fetchWithRetry(ctx, client, limiter, rawURL, cfg)
+				}
+				// Освобождаем слот до emit: медленный читатель RunStream
+				// не должен держать слоты семафора.
+				<-sem
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
 
-			title, err := fetchTitle(client, rawURL)
-			results <- Result{URL: rawURL, Title: title, Err: err}
-		}(u)
+			emit(i, Result{
+				URL:           rawURL,
+				Title:         page.Title,
+				Description:   page.Description,
+				H1:            page.H1,
+				StatusCode:    page.StatusCode,
+				ContentLength: page.ContentLength,
+				Err:           err,
+			})
+		}(i, u)
 	}
 
-	// ----- Горутина-«закрыватель» -----
-	// Ждёт завершения всех воркеров, затем закрывает канал results,
-	// чтобы агрегатор (range) корректно завершился.
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+	return &wg
+}
 
-	// ----- Агрегация результатов -----
-	// Читаем из канала до его закрытия. Это происходит в текущей горутине,
-	// поэтому функция Run сама блокируется, пока все результаты не будут собраны.
-	var collected []Result
-	for r := range results {
-		collected = append(collected, r)
+// ---------- Внутренние функции ----------
+
+// errRequestFailed отмечает сетевые ошибки (соединение, DNS, таймаут),
+// после которых запрос имеет смысл повторить.
+var errRequestFailed = errors.New("request failed")
+
+// fetchWithRetry вызывает fetchPage и при временной ошибке повторяет запрос
+// до cfg.MaxRetries раз. Пауза перед n-м повтором — RetryBackoff * 2^(n-1).
+// Каждая попытка ждёт своей очереди в limiter. Возвращается ошибка последней
+// попытки; после отмены ctx повторов нет.
+func fetchWithRetry(ctx context.Context, client *http.Client, limiter *hostLimiter, rawURL string, cfg Config) (fetchResult, error) {
+	if err := limiter.Wait(ctx, rawURL); err != nil {
+		return fetchResult{}, err
+	}
+	page, err := fetchPage(ctx, client, rawURL, cfg)
+	backoff := cfg.RetryBackoff
+	for attempt := 0; attempt < cfg.MaxRetries && retryable(err); attempt++ {
+		if err := sleepContext(ctx, backoff); err != nil {
+			return page, err
+		}
+		backoff *= 2
+		if err := limiter.Wait(ctx, rawURL); err != nil {
+			return page, err
+		}
+		page, err = fetchPage(ctx, client, rawURL, cfg)
 	}
+	return page, err
+}
 
-	return collected
+// sleepContext ждёт d или отмены ctx; в последнем случае возвращает ctx.Err().
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-// ---------- Внутренние функции ----------
+// retryable сообщает, стоит ли повторить запрос: да для сетевых ошибок и
+// ответов 5xx, нет для 4xx, ошибок парсинга и успеха.
+func retryable(err error) bool {
+	if errors.Is(err, errRequestFailed) {
+		return true
+	}
+	var se *StatusError
+	return errors.As(err, &se) && se.Code >= 500
+}
 
-// fetchTitle выполняет GET-запрос и извлекает содержимое <title> из HTML.
-func fetchTitle(client *http.Client, rawURL string) (string, error) {
-	// Нормализуем URL: если нет схемы — подставляем https://.
+// normalizeURL подставляет схему https://, если её нет.
+func normalizeURL(rawURL string) string {
 	if !strings.HasPrefix(rawURL, "http://") && !strings.HasPrefix(rawURL, "https://") {
-		rawURL = "https://" + rawURL
+		return "https://" + rawURL
 	}
+	return rawURL
+}
 
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, rawURL, nil)
+// fetchResult — итог одной попытки загрузки: сведения о странице и
+// параметры ответа.
+type fetchResult struct {
+	PageInfo
+	StatusCode    int
+	ContentLength int64
+}
+
+// fetchPage выполняет GET-запрос с заголовками из cfg и извлекает из HTML
+// сведения о странице. Код ответа и размер тела заполняются, даже если
+// ответ не 200.
+func fetchPage(ctx context.Context, client *http.Client, rawURL string, cfg Config) (fetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, normalizeURL(rawURL), nil)
 	if err != nil {
-		return "", fmt.Errorf("bad URL: %w", err)
+		return fetchResult{}, fmt.Errorf("bad URL: %w", err)
 	}
-	req.Header.Set("User-Agent", "GoWebScraper/1.0")
+	setHeaders(req, cfg)
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
+		return fetchResult{}, fmt.Errorf("%w: %w", errRequestFailed, err)
 	}
 	defer resp.Body.Close()
 
+	page := fetchResult{StatusCode: resp.StatusCode, ContentLength: max(resp.ContentLength, 0)}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
+		return page, &StatusError{Code: resp.StatusCode}
 	}
 
 	// Ограничиваем чтение 1 МБ — защищает от огромных страниц при парсинге.
-	limited := io.LimitReader(resp.Body, 1<<20)
-	return extractTitle(limited)
+	body := &countingReader{r: io.LimitReader(resp.Body, 1<<20)}
+	page.PageInfo, err = extractPageInfo(body)
+	if resp.ContentLength < 0 {
+		// Content-Length неизвестен (chunked) — дочитываем тело в пределах
+		// лимита и берём число прочитанных байт.
+		io.Copy(io.Discard, body)
+		page.ContentLength = body.n
+	}
+	return page, err
 }
 
-// extractTitle парсит HTML-поток и возвращает текст первого элемента <title>.
-// Используется потоковый (SAX-подобный) парсер golang.org/x/net/html —
-// он не загружает всё дерево в память.
-func extractTitle(r io.Reader) (string, error) {
+// countingReader считает прочитанные байты.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// extractPageInfo за один проход по HTML-потоку извлекает текст первого
+// <title>, content первого <meta name="description"> и текст первого <h1>
+// (включая вложенные теги). Используется потоковый (SAX-подобный) парсер
+// golang.org/x/net/html — он не загружает всё дерево в память.
+//
+// Отсутствие description или h1 — не ошибка: поля остаются пустыми.
+// Ошибка возвращается, только если нет <title>; уже найденные поля при этом
+// всё равно заполнены.
+func extractPageInfo(r io.Reader) (PageInfo, error) {
 	tokenizer := html.NewTokenizer(r)
 
-	for {
+	var (
+		info                     PageInfo
+		hasTitle, hasDesc, hasH1 bool
+		inH1                     bool // внутри первого <h1>
+		h1Text                   strings.Builder
+	)
+	for !(hasTitle && hasDesc && hasH1) {
 		tt := tokenizer.Next()
 		switch tt {
 		case html.ErrorToken:
 			err := tokenizer.Err()
-			if err == io.EOF {
-				return "", fmt.Errorf("title not found")
+			if inH1 {
+				info.H1 = normalizeSpace(h1Text.String())
+			}
+			if err != io.EOF {
+				return info, fmt.Errorf("parse error: %w", err)
 			}
-			return "", fmt.Errorf("parse error: %w", err)
+			if !hasTitle {
+				return info, fmt.Errorf("title not found")
+			}
+			return info, nil
 
-		case html.StartTagToken:
-			tn, _ := tokenizer.TagName()
-			if string(tn) == "title" {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tn, hasAttr := tokenizer.TagName()
+			switch string(tn) {
+			case "title":
+				if hasTitle {
+					continue
+				}
+				hasTitle = true
 				// Следующий токен — текстовое содержимое <title>.
 				if tokenizer.Next() == html.TextToken {
-					return strings.TrimSpace(string(tokenizer.Text())), nil
+					info.Title = strings.TrimSpace(string(tokenizer.Text()))
+				}
+			case "meta":
+				if hasDesc || !hasAttr {
+					continue
+				}
+				if content, ok := metaDescription(tokenizer); ok {
+					info.Description = content
+					hasDesc = true
+				}
+			case "h1":
+				if !hasH1 && !inH1 && tt == html.StartTagToken {
+					inH1 = true
 				}
-				return "", nil // пустой <title></title>
 			}
+
+		case html.TextToken:
+			if inH1 {
+				h1Text.Write(tokenizer.Text())
+			}
+
+		case html.EndTagToken:
+			if tn, _ := tokenizer.TagName(); inH1 && string(tn) == "h1" {
+				info.H1 = normalizeSpace(h1Text.String())
+				inH1, hasH1 = false, true
+			}
+		}
+	}
+	return info, nil
+}
+
+// metaDescription читает атрибуты текущего тега <meta> и возвращает content,
+// если это name="description" (имя без учёта регистра).
+func metaDescription(z *html.Tokenizer) (string, bool) {
+	var name, content string
+	for {
+		key, val, more := z.TagAttr()
+		switch string(key) {
+		case "name":
+			name = string(val)
+		case "content":
+			content = string(val)
+		}
+		if !more {
+			break
 		}
 	}
+	if !strings.EqualFold(strings.TrimSpace(name), "description") {
+		return "", false
+	}
+	return normalizeSpace(content), true
+}
+
+// normalizeSpace обрезает пробелы по краям и схлопывает внутренние.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
 }
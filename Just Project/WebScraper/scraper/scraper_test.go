@@ -1,10 +1,14 @@
 package scraper
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -14,7 +18,7 @@ const (
 	errOneResultFmt = "expected 1 result, got %d"
 )
 
-// ---------- Тесты extractTitle (парсинг HTML) ----------
+// ---------- Тесты extractPageInfo (парсинг HTML) ----------
 
 func TestExtractTitle(t *testing.T) {
 	tests := []struct {
@@ -57,18 +61,79 @@ func TestExtractTitle(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := extractTitle(strings.NewReader(tc.html))
+			info, err := extractPageInfo(strings.NewReader(tc.html))
 			if tc.wantErr {
 				if err == nil {
-					t.Fatalf("expected error, got nil (title=%q)", got)
+					t.Fatalf("expected error, got nil (title=%q)", info.Title)
 				}
 				return
 			}
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
+			if info.Title != tc.want {
+				t.Errorf("title = %q, want %q", info.Title, tc.want)
+			}
+		})
+	}
+}
+
+func TestExtractPageInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		html    string
+		want    PageInfo
+		wantErr bool
+	}{
+		{
+			name: "all_fields",
+			html: `<html><head><title>Home</title>
+				<meta name="description" content="  A  test page ">
+				</head><body><h1>Welcome</h1><h1>Second</h1></body></html>`,
+			want: PageInfo{Title: "Home", Description: "A test page", H1: "Welcome"},
+		},
+		{
+			name: "meta_before_title_self_closing",
+			html: `<head><meta charset="utf-8"/><META NAME="Description" CONTENT="Desc"/><title>T</title></head>`,
+			want: PageInfo{Title: "T", Description: "Desc"},
+		},
+		{
+			name: "other_meta_ignored",
+			html: `<head><meta name="keywords" content="go"><meta property="og:description" content="og"><title>T</title></head>`,
+			want: PageInfo{Title: "T"},
+		},
+		{
+			name: "h1_with_nested_tags",
+			html: `<title>T</title><body><h1>Hello, <a href="/">big
+				<b>world</b></a>!</h1></body>`,
+			want: PageInfo{Title: "T", H1: "Hello, big world!"},
+		},
+		{
+			name: "first_title_wins",
+			html: `<title>First</title><svg><title>Icon</title></svg><h1>H</h1>`,
+			want: PageInfo{Title: "First", H1: "H"},
+		},
+		{
+			name: "unclosed_h1",
+			html: `<title>T</title><h1>Dangling`,
+			want: PageInfo{Title: "T", H1: "Dangling"},
+		},
+		{
+			name:    "missing_title_keeps_other_fields",
+			html:    `<meta name="description" content="D"><h1>H</h1>`,
+			want:    PageInfo{Description: "D", H1: "H"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := extractPageInfo(strings.NewReader(tc.html))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tc.wantErr)
+			}
 			if got != tc.want {
-				t.Errorf("title = %q, want %q", got, tc.want)
+				t.Errorf("info = %+v, want %+v", got, tc.want)
 			}
 		})
 	}
@@ -109,6 +174,67 @@ func TestRunSingleURL(t *testing.T) {
 	}
 }
 
+func TestRunPageInfo(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><head><title>Shop</title><meta name="description" content="Best prices"></head>`+
+			`<body><h1>Catalog</h1></body></html>`)
+	}))
+	defer srv.Close()
+
+	results := Run([]string{srv.URL}, DefaultConfig())
+
+	if len(results) != 1 {
+		t.Fatalf(errOneResultFmt, len(results))
+	}
+	r := results[0]
+	if r.Err != nil || r.Title != "Shop" || r.Description != "Best prices" || r.H1 != "Catalog" {
+		t.Errorf("unexpected result: %+v", r)
+	}
+}
+
+func TestRunStatusAndLength(t *testing.T) {
+	const page = "<html><head><title>Sized</title></head></html>"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			http.Error(w, "gone", http.StatusNotFound)
+		case "/chunked":
+			fmt.Fprint(w, page)
+			w.(http.Flusher).Flush() // без Content-Length
+		default:
+			fmt.Fprint(w, page)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path       string
+		wantStatus int
+		wantLength int64
+		wantErr    bool
+	}{
+		{"/", http.StatusOK, int64(len(page)), false},
+		{"/chunked", http.StatusOK, int64(len(page)), false},
+		{"/missing", http.StatusNotFound, int64(len("gone\n")), true},
+	}
+	for _, tc := range tests {
+		results := Run([]string{srv.URL + tc.path}, Config{MaxWorkers: 1, Timeout: 2 * time.Second})
+		r := results[0]
+		if (r.Err != nil) != tc.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tc.path, r.Err, tc.wantErr)
+		}
+		if r.StatusCode != tc.wantStatus || r.ContentLength != tc.wantLength {
+			t.Errorf("%s: status = %d, length = %d, want %d and %d",
+				tc.path, r.StatusCode, r.ContentLength, tc.wantStatus, tc.wantLength)
+		}
+	}
+
+	results := Run([]string{"http://localhost:1"}, Config{MaxWorkers: 1, Timeout: time.Second})
+	if results[0].StatusCode != 0 || results[0].ContentLength != 0 {
+		t.Errorf("expected zero status and length without response, got %+v", results[0])
+	}
+}
+
 func TestRunMultipleURLs(t *testing.T) {
 	titles := []string{"Alpha", "Beta", "Gamma", "Delta"}
 	var urls []string
@@ -148,6 +274,35 @@ func TestRunMultipleURLs(t *testing.T) {
 	}
 }
 
+func TestRunPreserveOrder(t *testing.T) {
+	// Первые URL отвечают дольше, чтобы порядок завершения отличался от входного.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/"))
+		time.Sleep(time.Duration(10-n) * 10 * time.Millisecond)
+		fmt.Fprintf(w, "<title>Page %d</title>", n)
+	}))
+	defer srv.Close()
+
+	var urls []string
+	for i := 0; i < 10; i++ {
+		urls = append(urls, fmt.Sprintf("%s/%d", srv.URL, i))
+	}
+
+	results := Run(urls, Config{MaxWorkers: 4, Timeout: 5 * time.Second, PreserveOrder: true})
+
+	if len(results) != len(urls) {
+		t.Fatalf("expected %d results, got %d", len(urls), len(results))
+	}
+	for i, r := range results {
+		if r.URL != urls[i] {
+			t.Errorf("results[%d].URL = %q, want %q", i, r.URL, urls[i])
+		}
+		if want := fmt.Sprintf("Page %d", i); r.Err != nil || r.Title != want {
+			t.Errorf("results[%d] = %+v, want title %q", i, r, want)
+		}
+	}
+}
+
 func TestRunTimeout(t *testing.T) {
 	srv := newSlowServer(3 * time.Second)
 	defer srv.Close()
@@ -201,3 +356,303 @@ func TestRunConcurrencyLimit(t *testing.T) {
 		}
 	}
 }
+
+// ---------- Тесты повторов ----------
+
+// newFlakyServer отвечает кодом failCode на первые failures запросов,
+// затем отдаёт страницу; hits считает все запросы.
+func newFlakyServer(failures, failCode int, hits *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(hits.Add(1)) <= failures {
+			w.WriteHeader(failCode)
+			return
+		}
+		fmt.Fprintf(w, "<html><head><title>%s</title></head></html>", testPageTitle)
+	}))
+}
+
+func TestRunRetrySucceeds(t *testing.T) {
+	var hits atomic.Int32
+	srv := newFlakyServer(2, http.StatusServiceUnavailable, &hits)
+	defer srv.Close()
+
+	cfg := Config{MaxWorkers: 1, Timeout: 2 * time.Second, MaxRetries: 2, RetryBackoff: 10 * time.Millisecond}
+	start := time.Now()
+	results := Run([]string{srv.URL}, cfg)
+
+	if len(results) != 1 {
+		t.Fatalf(errOneResultFmt, len(results))
+	}
+	if results[0].Err != nil {
+		t.Fatalf("unexpected error: %v", results[0].Err)
+	}
+	if results[0].Title != testPageTitle {
+		t.Errorf("title = %q, want %q", results[0].Title, testPageTitle)
+	}
+	if got := hits.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+	// Паузы 10ms + 20ms — экспоненциальный рост.
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("elapsed = %v, want at least 30ms of backoff", elapsed)
+	}
+}
+
+func TestRunRetryExhausted(t *testing.T) {
+	var hits atomic.Int32
+	srv := newFlakyServer(10, http.StatusBadGateway, &hits)
+	defer srv.Close()
+
+	cfg := Config{MaxWorkers: 1, Timeout: 2 * time.Second, MaxRetries: 1, RetryBackoff: time.Millisecond}
+	results := Run([]string{srv.URL}, cfg)
+
+	var se *StatusError
+	if !errors.As(results[0].Err, &se) || se.Code != http.StatusBadGateway {
+		t.Fatalf("expected HTTP 502 error, got %v", results[0].Err)
+	}
+	if got := hits.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestRunNoRetryOn4xx(t *testing.T) {
+	var hits atomic.Int32
+	srv := newFlakyServer(10, http.StatusNotFound, &hits)
+	defer srv.Close()
+
+	cfg := Config{MaxWorkers: 1, Timeout: 2 * time.Second, MaxRetries: 3, RetryBackoff: time.Millisecond}
+	results := Run([]string{srv.URL}, cfg)
+
+	if results[0].Err == nil || results[0].Err.Error() != "HTTP 404" {
+		t.Fatalf("expected HTTP 404 error, got %v", results[0].Err)
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"network", fmt.Errorf("%w: connection reset", errRequestFailed), true},
+		{"503", &StatusError{Code: 503}, true},
+		{"500", &StatusError{Code: 500}, true},
+		{"404", &StatusError{Code: 404}, false},
+		{"parse", errors.New("title not found"), false},
+	}
+	for _, tc := range tests {
+		if got := retryable(tc.err); got != tc.want {
+			t.Errorf("%s: retryable = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// ---------- Тесты заголовков ----------
+
+// newEchoServer возвращает в <title> значения заголовков User-Agent
+// и Accept-Language через "|".
+func newEchoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "<title>%s|%s</title>", r.Header.Get("User-Agent"), r.Header.Get("Accept-Language"))
+	}))
+}
+
+func TestRunHeaders(t *testing.T) {
+	srv := newEchoServer()
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"default_agent", Config{}, userAgent + "|"},
+		{"custom_agent", Config{UserAgent: "AuditBot/2.0"}, "AuditBot/2.0|"},
+		{"extra_header", Config{Headers: map[string]string{"Accept-Language": "ru-RU"}}, userAgent + "|ru-RU"},
+		{"agent_from_headers", Config{Headers: map[string]string{"User-Agent": "HeaderBot"}}, "HeaderBot|"},
+		{
+			"agent_field_wins",
+			Config{UserAgent: "FieldBot", Headers: map[string]string{"User-Agent": "HeaderBot", "Accept-Language": "en"}},
+			"FieldBot|en",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.cfg.MaxWorkers, tc.cfg.Timeout = 1, 2*time.Second
+			results := Run([]string{srv.URL}, tc.cfg)
+			if results[0].Err != nil {
+				t.Fatalf("unexpected error: %v", results[0].Err)
+			}
+			if results[0].Title != tc.want {
+				t.Errorf("echoed headers = %q, want %q", results[0].Title, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunHeadersOnRetry(t *testing.T) {
+	var hits, missing atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			missing.Add(1)
+		}
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "<title>OK</title>")
+	}))
+	defer srv.Close()
+
+	cfg := Config{
+		MaxWorkers:   1,
+		Timeout:      2 * time.Second,
+		MaxRetries:   1,
+		RetryBackoff: time.Millisecond,
+		Headers:      map[string]string{"X-Token": "secret"},
+	}
+	results := Run([]string{srv.URL}, cfg)
+
+	if results[0].Err != nil {
+		t.Fatalf("unexpected error: %v", results[0].Err)
+	}
+	if hits.Load() != 2 || missing.Load() != 0 {
+		t.Errorf("requests = %d, without header = %d; want 2 and 0", hits.Load(), missing.Load())
+	}
+}
+
+// ---------- Тесты RunContext (отмена) ----------
+
+// newHangingServer держит запрос, пока клиент не отменит его (или 5 секунд);
+// started получает сигнал о каждом начатом запросе.
+func newHangingServer(started chan<- struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+}
+
+func TestRunContextCancel(t *testing.T) {
+	started := make(chan struct{}, 10)
+	srv := newHangingServer(started)
+	defer srv.Close()
+
+	urls := []string{srv.URL + "/1", srv.URL + "/2", srv.URL + "/3", srv.URL + "/4"}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		// Ждём, пока запросы двух воркеров дойдут до сервера; остальные URL стоят в очереди семафора.
+		<-started
+		<-started
+		cancel()
+	}()
+
+	cfg := Config{MaxWorkers: 2, Timeout: 10 * time.Second, MaxRetries: 3, RetryBackoff: time.Second, PreserveOrder: true}
+	start := time.Now()
+	results := RunContext(ctx, urls, cfg)
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("RunContext took %v after cancel, want prompt return", elapsed)
+	}
+	if len(results) != len(urls) {
+		t.Fatalf("expected %d results, got %d", len(urls), len(results))
+	}
+	for i, r := range results {
+		if r.URL != urls[i] {
+			t.Errorf("results[%d].URL = %q, want %q", i, r.URL, urls[i])
+		}
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%s: expected context.Canceled, got %v", r.URL, r.Err)
+		}
+	}
+	if n := len(started); n != 0 {
+		t.Errorf("%d queued requests reached the server after cancel", n)
+	}
+}
+
+func TestRunContextAlreadyCancelled(t *testing.T) {
+	started := make(chan struct{}, 10)
+	srv := newHangingServer(started)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := RunContext(ctx, []string{srv.URL, srv.URL}, DefaultConfig())
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", r.Err)
+		}
+	}
+	if len(started) != 0 {
+		t.Error("no request should be sent with a cancelled context")
+	}
+}
+
+func TestRunContextDeadline(t *testing.T) {
+	started := make(chan struct{}, 10)
+	srv := newHangingServer(started)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	results := RunContext(ctx, []string{srv.URL}, Config{MaxWorkers: 1, Timeout: 10 * time.Second})
+
+	if !errors.Is(results[0].Err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got %v", results[0].Err)
+	}
+}
+
+// ---------- Тесты RunStream ----------
+
+func TestRunStream(t *testing.T) {
+	fast := newTestServer("Fast")
+	defer fast.Close()
+	slow := newSlowServer(500 * time.Millisecond)
+	defer slow.Close()
+
+	urls := []string{slow.URL, fast.URL, fast.URL, fast.URL}
+	start := time.Now()
+	stream := RunStream(urls, Config{MaxWorkers: 4, Timeout: 5 * time.Second})
+
+	var count int
+	var firstAt time.Duration
+	for r := range stream {
+		if count == 0 {
+			firstAt = time.Since(start)
+		}
+		count++
+		if r.Err != nil {
+			t.Errorf("error for %s: %v", r.URL, r.Err)
+		}
+	}
+
+	if count != len(urls) {
+		t.Errorf("received %d results, want %d", count, len(urls))
+	}
+	// Быстрые результаты приходят, не дожидаясь медленного сервера.
+	if firstAt >= 500*time.Millisecond {
+		t.Errorf("first result after %v, want before the slow server answers", firstAt)
+	}
+}
+
+func TestRunStreamEmpty(t *testing.T) {
+	var count int
+	for range RunStream(nil, DefaultConfig()) {
+		count++
+	}
+	if count != 0 {
+		t.Errorf("received %d results for empty input", count)
+	}
+}